@@ -0,0 +1,471 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+// Scopes of api keys
+const (
+	scopeParse = "parse"
+	scopeRead  = "read"
+	scopeAdmin = "admin"
+)
+
+// allScopes are granted to the basic auth account
+var allScopes = []string{scopeParse, scopeRead, scopeAdmin}
+
+// apiKeyPrefix starts every api key, followed by the key id and the secret
+const apiKeyPrefix = "dsk_"
+
+var (
+	errUnauthenticated = errors.New("invalid credentials")
+	errMissingScope    = errors.New("credentials lack the required scope")
+)
+
+var keyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "demo_stats_api_key_requests_total",
+	Help: "Requests authenticated with an api key by key id.",
+}, []string{"key"})
+
+// rateLimitError signals that the rate limit of a key is exceeded
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return "rate limit exceeded, retry in " + e.retryAfter.String()
+}
+
+// apiKey is a stored api key. Only the SHA256 hash of the key is kept
+type apiKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes"`
+	RateLimit int       `json:"rate_limit"`
+	Created   time.Time `json:"created"`
+}
+
+// apiKeyInfo is an api key as listed by the admin endpoint, with its usage
+// since the start of the service
+type apiKeyInfo struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rate_limit"`
+	Created   time.Time  `json:"created"`
+	Requests  int64      `json:"requests"`
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+}
+
+// principal is the authenticated caller of a request
+type principal struct {
+	Name   string
+	KeyID  string
+	Scopes []string
+}
+
+// hasScope reports whether the caller was granted the scope
+func (p principal) hasScope(scope string) bool {
+	return containsString(p.Scopes, scope)
+}
+
+// keyUsage counts the requests of a key and limits their rate
+type keyUsage struct {
+	requests int64
+	lastUsed time.Time
+	tokens   float64
+	refilled time.Time
+}
+
+// keyStore holds the api keys, persisted to a JSON file if a path is set.
+// The file is shared with the keys command, changes are made under a file
+// lock and changes of other processes are picked up
+type keyStore struct {
+	mu    sync.Mutex
+	path  string
+	file  os.FileInfo
+	keys  map[string]apiKey
+	usage map[string]*keyUsage
+}
+
+// newKeyStore constructor for a key store. The key file is loaded if it
+// exists, an empty path keeps the keys in memory only
+func newKeyStore(path string) (*keyStore, error) {
+	ks := &keyStore{
+		path:  path,
+		keys:  make(map[string]apiKey),
+		usage: make(map[string]*keyUsage),
+	}
+	if path == "" {
+		return ks, nil
+	}
+	if err := ks.load(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// load replaces the keys with the ones in the key file
+func (ks *keyStore) load() error {
+	info, err := os.Stat(ks.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(ks.path)
+	if err != nil {
+		return err
+	}
+
+	keys := []apiKey{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &keys); err != nil {
+			return fmt.Errorf("invalid api key file %s: %v", ks.path, err)
+		}
+	}
+	ks.keys = make(map[string]apiKey, len(keys))
+	for _, k := range keys {
+		ks.keys[k.ID] = k
+	}
+	ks.file = info
+	return nil
+}
+
+// refresh loads the key file again if another process changed it. Saving
+// replaces the file, so a changed file is a different one
+func (ks *keyStore) refresh() {
+	if ks.path == "" {
+		return
+	}
+	info, err := os.Stat(ks.path)
+	if err != nil || (ks.file != nil && os.SameFile(info, ks.file) && info.ModTime().Equal(ks.file.ModTime())) {
+		return
+	}
+	if err := ks.load(); err != nil {
+		log.Warning("reloading api keys failed: ", err)
+	}
+}
+
+// update applies change to the keys of the key file and saves them if
+// change returns true. The file stays locked in between, so the server and
+// the keys command don't overwrite each other's keys
+func (ks *keyStore) update(change func() bool) error {
+	if ks.path == "" {
+		change()
+		return nil
+	}
+	unlock, err := lockFile(ks.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := ks.load(); err != nil {
+		return err
+	}
+	if !change() {
+		return nil
+	}
+	if err := ks.save(); err != nil {
+		// Back to the keys on disk
+		_ = ks.load()
+		return err
+	}
+	if info, err := os.Stat(ks.path); err == nil {
+		ks.file = info
+	}
+	return nil
+}
+
+// create adds a new api key with the scopes and a rate limit in requests per
+// minute, zero means unlimited. Returns the key, it can't be recovered later
+func (ks *keyStore) create(name string, scopes []string, rateLimit int) (apiKeyInfo, string, error) {
+	if len(scopes) == 0 {
+		return apiKeyInfo{}, "", errors.New("at least one scope is required")
+	}
+	for _, s := range scopes {
+		if !containsString(allScopes, s) {
+			return apiKeyInfo{}, "", fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	if rateLimit < 0 {
+		return apiKeyInfo{}, "", errors.New("rate limit must not be negative")
+	}
+
+	id, secret := make([]byte, 8), make([]byte, 24)
+	if _, err := rand.Read(id); err != nil {
+		return apiKeyInfo{}, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return apiKeyInfo{}, "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(id) + "_" + hex.EncodeToString(secret)
+
+	k := apiKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hashKey(key),
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		Created:   time.Now().UTC(),
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	err := ks.update(func() bool {
+		ks.keys[k.ID] = k
+		return true
+	})
+	if err != nil {
+		return apiKeyInfo{}, "", err
+	}
+	return ks.info(k), key, nil
+}
+
+// revoke removes an api key, returns false if there is none with the id
+func (ks *keyStore) revoke(id string) (bool, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	var found bool
+	err := ks.update(func() bool {
+		_, found = ks.keys[id]
+		delete(ks.keys, id)
+		return found
+	})
+	if err != nil || !found {
+		return false, err
+	}
+	delete(ks.usage, id)
+	return true, nil
+}
+
+// list returns all api keys sorted by creation
+func (ks *keyStore) list() []apiKeyInfo {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.refresh()
+	out := []apiKeyInfo{}
+	for _, k := range ks.keys {
+		out = append(out, ks.info(k))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+func (ks *keyStore) info(k apiKey) apiKeyInfo {
+	info := apiKeyInfo{ID: k.ID, Name: k.Name, Scopes: k.Scopes, RateLimit: k.RateLimit, Created: k.Created}
+	if u, ok := ks.usage[k.ID]; ok && u.requests > 0 {
+		lastUsed := u.lastUsed
+		info.Requests = u.requests
+		info.LastUsed = &lastUsed
+	}
+	return info
+}
+
+// authenticate checks an api key, counts the request and applies the rate
+// limit of the key
+func (ks *keyStore) authenticate(key string, now time.Time) (principal, error) {
+	parts := strings.SplitN(strings.TrimPrefix(key, apiKeyPrefix), "_", 2)
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(parts) != 2 {
+		return principal{}, errUnauthenticated
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	// Keys may have been created or revoked by the keys command
+	ks.refresh()
+	k, ok := ks.keys[parts[0]]
+	if !ok || subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(k.Hash)) != 1 {
+		return principal{}, errUnauthenticated
+	}
+
+	u, ok := ks.usage[k.ID]
+	if !ok {
+		u = &keyUsage{tokens: float64(k.RateLimit), refilled: now}
+		ks.usage[k.ID] = u
+	}
+	if k.RateLimit > 0 {
+		// Token bucket refilled with the rate limit per minute, holding at
+		// most a minute worth of requests
+		perSecond := float64(k.RateLimit) / 60
+		u.tokens = math.Min(float64(k.RateLimit), u.tokens+now.Sub(u.refilled).Seconds()*perSecond)
+		u.refilled = now
+		if u.tokens < 1 {
+			wait := time.Duration((1 - u.tokens) / perSecond * float64(time.Second))
+			return principal{}, &rateLimitError{retryAfter: wait}
+		}
+		u.tokens--
+	}
+	u.requests++
+	u.lastUsed = now
+	keyRequests.WithLabelValues(k.ID).Inc()
+
+	return principal{Name: k.Name, KeyID: k.ID, Scopes: k.Scopes}, nil
+}
+
+// save writes the keys to the key file, see update
+func (ks *keyStore) save() error {
+	if ks.path == "" {
+		return nil
+	}
+	keys := []apiKey{}
+	for _, k := range ks.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Created.Before(keys[j].Created) })
+	return writeJSONFile(ks.path, keys)
+}
+
+// hashKey returns the hex encoded SHA256 hash of an api key. Keys are random
+// enough for a fast hash
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticator checks the credentials of http and grpc requests. Requests
+// authenticate with an api key as "Bearer" token or in the X-API-Key header,
+// or with the basic auth account if one is configured
+type authenticator struct {
+	keys      *keyStore
+	basicUser string
+	basicPass string
+}
+
+// authenticatorFromEnv creates the authenticator. Basic auth is only enabled
+// if both DEMO_STATS_USER and DEMO_STATS_PASSWORD are set
+func authenticatorFromEnv() (*authenticator, error) {
+	path, _ := os.LookupEnv("DEMO_STATS_API_KEYS_FILE")
+	keys, err := newKeyStore(path)
+	if err != nil {
+		return nil, err
+	}
+	a := &authenticator{keys: keys}
+	a.basicUser, _ = os.LookupEnv("DEMO_STATS_USER")
+	a.basicPass, _ = os.LookupEnv("DEMO_STATS_PASSWORD")
+	return a, nil
+}
+
+// authenticate checks the value of an Authorization header, or the api key
+// of an X-API-Key header
+func (a *authenticator) authenticate(authorization string, apiKey string) (principal, error) {
+	switch {
+	case apiKey != "":
+		return a.keys.authenticate(apiKey, time.Now())
+	case strings.HasPrefix(authorization, "Bearer "):
+		return a.keys.authenticate(strings.TrimPrefix(authorization, "Bearer "), time.Now())
+	case strings.HasPrefix(authorization, "Basic "):
+		return a.basicAuth(strings.TrimPrefix(authorization, "Basic "))
+	}
+	return principal{}, errUnauthenticated
+}
+
+func (a *authenticator) basicAuth(credentials string) (principal, error) {
+	// An unset user or password must not allow empty credentials
+	if a.basicUser == "" || a.basicPass == "" {
+		return principal{}, errUnauthenticated
+	}
+	b, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return principal{}, errUnauthenticated
+	}
+	want := a.basicUser + ":" + a.basicPass
+	if subtle.ConstantTimeCompare(b, []byte(want)) != 1 {
+		return principal{}, errUnauthenticated
+	}
+	return principal{Name: a.basicUser, Scopes: allScopes}, nil
+}
+
+// require is a gin middleware rejecting requests without credentials granted
+// the scope
+func (a *authenticator) require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := a.authenticate(c.GetHeader("Authorization"), c.GetHeader("X-API-Key"))
+		var limitErr *rateLimitError
+		switch {
+		case errors.As(err, &limitErr):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(429, err.Error())
+			return
+		case err != nil:
+			if a.basicUser != "" && a.basicPass != "" {
+				c.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+			}
+			c.AbortWithStatusJSON(401, err.Error())
+			return
+		case !p.hasScope(scope):
+			c.AbortWithStatusJSON(403, errMissingScope.Error()+" "+scope)
+			return
+		}
+
+		entry := loggerFrom(c.Request.Context()).WithField("principal", p.Name)
+		if p.KeyID != "" {
+			entry = entry.WithField("key_id", p.KeyID)
+		}
+		c.Request = c.Request.WithContext(withLogger(c.Request.Context(), entry))
+		c.Next()
+	}
+}
+
+// registerKeyRoutes adds the endpoints to manage api keys
+func registerKeyRoutes(api *gin.RouterGroup, a *authenticator) {
+	admin := api.Group("/keys", a.require(scopeAdmin))
+	admin.GET("", func(c *gin.Context) {
+		c.JSON(200, a.keys.list())
+	})
+	admin.POST("", func(c *gin.Context) {
+		var body struct {
+			Name      string   `json:"name"`
+			Scopes    []string `json:"scopes"`
+			RateLimit int      `json:"rate_limit"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		info, key, err := a.keys.create(body.Name, body.Scopes, body.RateLimit)
+		if err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		c.JSON(201, struct {
+			apiKeyInfo
+			Key string `json:"key"`
+		}{info, key})
+	})
+	admin.DELETE("/:id", func(c *gin.Context) {
+		ok, err := a.keys.revoke(c.Param("id"))
+		if err != nil {
+			c.JSON(500, err.Error())
+			return
+		}
+		if !ok {
+			c.JSON(404, "api key not found")
+			return
+		}
+		c.Status(204)
+	})
+}
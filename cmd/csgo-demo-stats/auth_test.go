@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyStore(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "keys.json")
+	ks, err := newKeyStore(path)
+	assert.NoError(t, err)
+
+	_, _, err = ks.create("bad", []string{"write"}, 0)
+	assert.Error(t, err)
+	_, _, err = ks.create("none", nil, 0)
+	assert.Error(t, err)
+
+	info, key, err := ks.create("ci", []string{scopeParse}, 0)
+	assert.NoError(t, err)
+
+	// Only the hash is stored, the keys survive a restart
+	b, _ := ioutil.ReadFile(path)
+	assert.NotContains(t, string(b), key)
+	ks, err = newKeyStore(path)
+	assert.NoError(t, err)
+
+	p, err := ks.authenticate(key, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, principal{Name: "ci", KeyID: info.ID, Scopes: []string{scopeParse}}, p)
+	_, err = ks.authenticate(key+"0", time.Now())
+	assert.Equal(t, errUnauthenticated, err)
+	_, err = ks.authenticate("dsk_", time.Now())
+	assert.Equal(t, errUnauthenticated, err)
+
+	list := ks.list()
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].Requests)
+
+	ok, err := ks.revoke(info.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = ks.authenticate(key, time.Now())
+	assert.Equal(t, errUnauthenticated, err)
+}
+
+func TestKeyRateLimit(t *testing.T) {
+	ks, _ := newKeyStore("")
+	_, key, err := ks.create("limited", []string{scopeParse}, 2)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err = ks.authenticate(key, now)
+		assert.NoError(t, err)
+	}
+	_, err = ks.authenticate(key, now)
+	limitErr, ok := err.(*rateLimitError)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, limitErr.retryAfter)
+
+	// Two per minute, one more after 30 seconds
+	_, err = ks.authenticate(key, now.Add(30*time.Second))
+	assert.NoError(t, err)
+}
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth := testAuthenticator(t)
+	_, parseKey, _ := auth.keys.create("parser", []string{scopeParse}, 0)
+	_, limitedKey, _ := auth.keys.create("limited", []string{scopeAdmin}, 1)
+
+	r := gin.New()
+	r.GET("/", auth.require(scopeAdmin), func(c *gin.Context) { c.Status(200) })
+	do := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, 401, do("", "").Code)
+	assert.Equal(t, 200, do("Authorization", "Basic dXNlcjpwYXNz").Code)
+	assert.Equal(t, 401, do("Authorization", "Basic dXNlcjpwYXN0").Code)
+	assert.Equal(t, 403, do("Authorization", "Bearer "+parseKey).Code)
+	assert.Equal(t, 200, do("X-API-Key", limitedKey).Code)
+	w := do("X-API-Key", limitedKey)
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestBasicAuthUnset(t *testing.T) {
+	auth := testAuthenticator(t)
+	auth.basicUser, auth.basicPass = "", ""
+
+	// base64(":")
+	_, err := auth.authenticate("Basic Og==", "")
+	assert.Equal(t, errUnauthenticated, err)
+
+	auth.basicPass = "pass"
+	// base64(":pass")
+	_, err = auth.authenticate("Basic OnBhc3M=", "")
+	assert.Equal(t, errUnauthenticated, err)
+}
+
+func TestKeyStoreShared(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "keys.json")
+
+	// The server and the keys command share the key file
+	server, err := newKeyStore(path)
+	assert.NoError(t, err)
+	serverInfo, serverKey, err := server.create("server", []string{scopeRead}, 0)
+	assert.NoError(t, err)
+
+	cli, err := newKeyStore(path)
+	assert.NoError(t, err)
+	cliInfo, cliKey, err := cli.create("cli", []string{scopeParse}, 0)
+	assert.NoError(t, err)
+
+	// A key created by the server afterwards doesn't drop the one of the
+	// keys command, which works without a restart
+	_, _, err = server.create("later", []string{scopeRead}, 0)
+	assert.NoError(t, err)
+	_, err = server.authenticate(cliKey, time.Now())
+	assert.NoError(t, err)
+	reloaded, err := newKeyStore(path)
+	assert.NoError(t, err)
+	assert.Len(t, reloaded.list(), 3)
+
+	// Keys are revoked across processes too
+	ok, err := cli.revoke(serverInfo.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = server.authenticate(serverKey, time.Now())
+	assert.Equal(t, errUnauthenticated, err)
+	ok, err = server.revoke(cliInfo.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, cli.list(), 1)
+}
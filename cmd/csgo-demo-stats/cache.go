@@ -0,0 +1,394 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "demo_stats_cache_requests_total",
+	Help: "Result cache lookups by result, hit or miss.",
+}, []string{"result"})
+
+// resultCache keeps parsed matches by key. The most recently used matches
+// are kept in memory, and on disk up to maxDiskSize bytes if a directory is
+// set
+type resultCache struct {
+	size        int
+	dir         string
+	maxDiskSize int64
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	pruneMu sync.Mutex
+}
+
+// cacheEntry is an element of the lru list of the result cache
+type cacheEntry struct {
+	key   string
+	match *demostats.Match
+}
+
+// newResultCache constructor for a result cache keeping size matches in
+// memory. Matches are also stored in dir unless it is empty, the least
+// recently used are removed once the files exceed maxDiskSize bytes. 0 keeps
+// all of them
+func newResultCache(size int, dir string, maxDiskSize int64) (*resultCache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &resultCache{
+		size:        size,
+		dir:         dir,
+		maxDiskSize: maxDiskSize,
+		order:       list.New(),
+		entries:     map[string]*list.Element{},
+	}, nil
+}
+
+// resultCacheFromEnv creates the result cache. It keeps 100 matches in
+// memory and 1 GiB on disk by default, DEMO_STATS_CACHE_SIZE=0 disables the
+// cache unless a directory is set
+func resultCacheFromEnv() (*resultCache, error) {
+	size := 100
+	if v, ok := os.LookupEnv("DEMO_STATS_CACHE_SIZE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_CACHE_SIZE %q", v)
+		}
+		size = n
+	}
+	maxDiskSize := int64(1 << 30)
+	if v, ok := os.LookupEnv("DEMO_STATS_CACHE_DIR_MAX_SIZE"); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_CACHE_DIR_MAX_SIZE %q", v)
+		}
+		maxDiskSize = n
+	}
+	dir, _ := os.LookupEnv("DEMO_STATS_CACHE_DIR")
+	if size == 0 && dir == "" {
+		return nil, nil
+	}
+	return newResultCache(size, dir, maxDiskSize)
+}
+
+// get returns the cached match of the key or nil. Matches only found on
+// disk are loaded into memory. A nil cache caches nothing
+func (rc *resultCache) get(key string) *demostats.Match {
+	if rc == nil {
+		return nil
+	}
+
+	rc.mu.Lock()
+	if e, ok := rc.entries[key]; ok {
+		rc.order.MoveToFront(e)
+		rc.mu.Unlock()
+		cacheRequests.WithLabelValues("hit").Inc()
+		return e.Value.(*cacheEntry).match
+	}
+	rc.mu.Unlock()
+
+	m := rc.load(key)
+	if m == nil {
+		cacheRequests.WithLabelValues("miss").Inc()
+		return nil
+	}
+	rc.add(key, m)
+	cacheRequests.WithLabelValues("hit").Inc()
+	return m
+}
+
+// put caches the match under the key
+func (rc *resultCache) put(key string, m *demostats.Match) error {
+	if rc == nil {
+		return nil
+	}
+	rc.add(key, m)
+	if rc.dir == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	// Concurrent puts of the same key must not write to the same file
+	f, err := ioutil.TempFile(rc.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), rc.path(key)); err != nil {
+		return err
+	}
+	return rc.prune()
+}
+
+// prune removes the least recently used files from the cache directory
+// until they take at most maxDiskSize bytes
+func (rc *resultCache) prune() error {
+	if rc.maxDiskSize == 0 {
+		return nil
+	}
+	rc.pruneMu.Lock()
+	defer rc.pruneMu.Unlock()
+
+	infos, err := ioutil.ReadDir(rc.dir)
+	if err != nil {
+		return err
+	}
+	var files []os.FileInfo
+	var total int64
+	for _, fi := range infos {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		files = append(files, fi)
+		total += fi.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, fi := range files {
+		if total <= rc.maxDiskSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(rc.dir, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= fi.Size()
+	}
+	return nil
+}
+
+// add puts the match into memory, evicting the least recently used match if
+// the cache is full
+func (rc *resultCache) add(key string, m *demostats.Match) {
+	if rc.size == 0 {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if e, ok := rc.entries[key]; ok {
+		e.Value.(*cacheEntry).match = m
+		rc.order.MoveToFront(e)
+		return
+	}
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, match: m})
+	if rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// load reads the match of the key from disk. Missing, broken and outdated
+// files are cache misses. The modification time of the file marks it used,
+// so it is pruned last
+func (rc *resultCache) load(key string) *demostats.Match {
+	if rc.dir == "" {
+		return nil
+	}
+	path := rc.path(key)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var m demostats.Match
+	if err := json.Unmarshal(b, &m); err != nil || m.SchemaVersion != demostats.SchemaVersion {
+		return nil
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return &m
+}
+
+// path returns the file of the key in the cache directory
+func (rc *resultCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(rc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// contentKey returns the cache key of a demo file, the SHA256 hash of its
+// content
+func contentKey(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteKey returns the cache key of a remote demo, its url and the ETag or
+// Last-Modified date of the remote
+func remoteKey(rawURL string, validator string) string {
+	return "url:" + rawURL + " " + validator
+}
+
+// withMatchID returns a copy of the cached match with the match id of the
+// current request
+func withMatchID(m *demostats.Match, matchID string) *demostats.Match {
+	c := *m
+	c.MatchID = matchID
+	return &c
+}
+
+// parseCached returns the cached match with the same content and options as
+// the demo file at path, or parses the file and caches the result. force
+// always parses the file. matchID is the id the parse assigns
+func (s *server) parseCached(ctx context.Context, path string, matchID string, force bool, opts demostats.Options, parse func() (*demostats.Match, error)) (*demostats.Match, error) {
+	if s.cache == nil {
+		return parse()
+	}
+	key, err := contentKey(path)
+	if err != nil {
+		return nil, err
+	}
+	key += chatVariant(opts)
+	if !force {
+		if m := s.cache.get(key); m != nil {
+			loggerFrom(ctx).WithField("key", key).Info("using cached result")
+			return withMatchID(m, matchID), nil
+		}
+	}
+
+	matchInfo, err := parse()
+	if err == nil {
+		if err := s.cache.put(key, matchInfo); err != nil {
+			loggerFrom(ctx).Warning("caching result failed: ", err)
+		}
+	}
+	return matchInfo, err
+}
+
+// parseFileCached parses the demo file at path with the result cache, see
+// parseCached
+func (s *server) parseFileCached(ctx context.Context, path string, force bool, opts demostats.Options) (*demostats.Match, error) {
+	return s.parseCached(ctx, path, demostats.MatchIDFromPath(path), force, opts, func() (*demostats.Match, error) {
+		return s.parseFile(ctx, path, opts)
+	})
+}
+
+// parseUpload saves an uploaded demo to a temporary file and parses it with
+// the result cache, see parseCached
+func (s *server) parseUpload(ctx context.Context, r io.Reader, force bool, opts demostats.Options) (*demostats.Match, error) {
+	if s.cache == nil {
+		return s.parse(ctx, r, opts)
+	}
+	f, err := ioutil.TempFile("", "demo-stats-*.dem")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if s.parseOpts.MaxSize > 0 {
+		r = io.LimitReader(r, s.parseOpts.MaxSize+1)
+	}
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return nil, err
+	}
+	if s.parseOpts.MaxSize > 0 && n > s.parseOpts.MaxSize {
+		return nil, demostats.ErrDemoTooLarge
+	}
+
+	return s.parseCached(ctx, f.Name(), "", force, opts, func() (*demostats.Match, error) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return s.parse(ctx, f, opts)
+	})
+}
+
+// cachedRemote returns the validator of a remote demo and its cached match,
+// if the ETag or Last-Modified date of the remote didn't change since it was
+// cached. The match is nil if the demo has to be downloaded
+func (s *server) cachedRemote(ctx context.Context, rawURL string, auth string, force bool, opts demostats.Options) (string, *demostats.Match) {
+	if s.cache == nil {
+		return "", nil
+	}
+	validator := s.remote.validator(ctx, rawURL, auth)
+	if validator == "" || force {
+		return validator, nil
+	}
+	m := s.cache.get(remoteKey(rawURL, validator) + chatVariant(opts))
+	if m != nil {
+		loggerFrom(ctx).WithField("url", rawURL).Info("using cached result")
+	}
+	return validator, m
+}
+
+// cacheRemote caches the match of a remote demo with the validator returned
+// by cachedRemote
+func (s *server) cacheRemote(ctx context.Context, rawURL string, validator string, opts demostats.Options, m *demostats.Match) {
+	if validator == "" {
+		return
+	}
+	if err := s.cache.put(remoteKey(rawURL, validator)+chatVariant(opts), m); err != nil {
+		loggerFrom(ctx).Warning("caching result failed: ", err)
+	}
+}
+
+// validator returns the strong ETag or the Last-Modified date of a remote
+// url from a HEAD request, or an empty string if the remote sends neither
+// or the request fails
+func (rc *remoteClient) validator(ctx context.Context, rawURL string, auth string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || rc.policy.checkURL(u) != nil {
+		return ""
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", u.String(), nil)
+	if err != nil {
+		return ""
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	return responseValidator(resp)
+}
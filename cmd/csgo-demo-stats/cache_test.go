@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "demo-stats-cache-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rc, err := newResultCache(2, dir, 0)
+	assert.NoError(t, err)
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, rc.put(key, &demostats.Match{SchemaVersion: demostats.SchemaVersion, MatchID: key}))
+	}
+
+	// "a" was evicted from memory but is still on disk
+	assert.Equal(t, 2, rc.order.Len())
+	assert.NotContains(t, rc.entries, "a")
+	assert.Equal(t, "a", rc.get("a").MatchID)
+	assert.NotContains(t, rc.entries, "b")
+
+	// A new cache finds the matches on disk
+	rc, err = newResultCache(2, dir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "c", rc.get("c").MatchID)
+	assert.Nil(t, rc.get("d"))
+
+	// Results of older schema versions are parsed again
+	assert.NoError(t, rc.put("old", &demostats.Match{SchemaVersion: demostats.SchemaVersion - 1}))
+	rc, _ = newResultCache(2, dir, 0)
+	assert.Nil(t, rc.get("old"))
+}
+
+func TestResultCacheDiskLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "demo-stats-cache-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	match := func(key string) *demostats.Match {
+		return &demostats.Match{SchemaVersion: demostats.SchemaVersion, MatchID: key}
+	}
+	b, _ := json.Marshal(match("a"))
+	// Room for two matches on disk, none in memory
+	rc, err := newResultCache(0, dir, int64(2*len(b)))
+	assert.NoError(t, err)
+	assert.NoError(t, rc.put("a", match("a")))
+	assert.NoError(t, rc.put("b", match("b")))
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(rc.path("a"), past, past))
+	assert.NoError(t, os.Chtimes(rc.path("b"), past.Add(time.Minute), past.Add(time.Minute)))
+
+	// Reading "a" makes "b" the least recently used
+	assert.NotNil(t, rc.get("a"))
+	assert.NoError(t, rc.put("c", match("c")))
+	assert.NotNil(t, rc.get("a"))
+	assert.Nil(t, rc.get("b"))
+	assert.NotNil(t, rc.get("c"))
+}
+
+func TestParseCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "demo-stats-cache-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	first := filepath.Join(dir, "1234_de_dust2.dem")
+	second := filepath.Join(dir, "5678_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(first, []byte("demo"), 0644))
+	assert.NoError(t, ioutil.WriteFile(second, []byte("demo"), 0644))
+
+	rc, _ := newResultCache(10, "", 0)
+	srv := &server{cache: rc}
+	parses := 0
+	parse := func(path string) func() (*demostats.Match, error) {
+		return func() (*demostats.Match, error) {
+			parses++
+			return &demostats.Match{MatchID: demostats.MatchIDFromPath(path)}, nil
+		}
+	}
+
+	m, err := srv.parseCached(context.Background(), first, "1234", false, demostats.Options{}, parse(first))
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", m.MatchID)
+
+	// Same content under another name, the match id is the one of the file
+	m, err = srv.parseCached(context.Background(), second, "5678", false, demostats.Options{}, parse(second))
+	assert.NoError(t, err)
+	assert.Equal(t, "5678", m.MatchID)
+	assert.Equal(t, 1, parses)
+
+	_, err = srv.parseCached(context.Background(), first, "1234", true, demostats.Options{}, parse(first))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, parses)
+}
+
+func TestCachedRemote(t *testing.T) {
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag.Load().(string))
+		w.Write([]byte("demo"))
+	}))
+	defer srv.Close()
+
+	rc, _ := newResultCache(10, "", 0)
+	s := &server{remote: testRemoteClient(), cache: rc}
+	url := srv.URL + "/1234_de_dust2.dem"
+
+	validator, m := s.cachedRemote(context.Background(), url, "", false, demostats.Options{})
+	assert.Equal(t, `"v1"`, validator)
+	assert.Nil(t, m)
+	s.cacheRemote(context.Background(), url, validator, demostats.Options{}, &demostats.Match{MatchID: "1234"})
+
+	_, m = s.cachedRemote(context.Background(), url, "", false, demostats.Options{})
+	assert.Equal(t, "1234", m.MatchID)
+	_, m = s.cachedRemote(context.Background(), url, "", true, demostats.Options{})
+	assert.Nil(t, m)
+
+	// A changed demo is downloaded again
+	etag.Store(`"v2"`)
+	validator, m = s.cachedRemote(context.Background(), url, "", false, demostats.Options{})
+	assert.Equal(t, `"v2"`, validator)
+	assert.Nil(t, m)
+}
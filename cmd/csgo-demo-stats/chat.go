@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+)
+
+// chatVariant returns the part of the cache key telling results with chat
+// apart. Filtered results depend on the word list
+func chatVariant(opts demostats.Options) string {
+	switch {
+	case !opts.Chat:
+		return ""
+	case len(opts.ChatWords) == 0:
+		return " chat"
+	}
+	sum := sha256.Sum256([]byte(strings.Join(opts.ChatWords, "\n")))
+	return " chat:" + hex.EncodeToString(sum[:8])
+}
+
+// chatWordsFromEnv reads the word list chat can be filtered by from the
+// file of DEMO_STATS_CHAT_WORDS_FILE, one word or phrase per line. Lines
+// starting with # are ignored
+func chatWordsFromEnv() ([]string, error) {
+	path, ok := os.LookupEnv("DEMO_STATS_CHAT_WORDS_FILE")
+	if !ok {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEMO_STATS_CHAT_WORDS_FILE: %v", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+// requestOptions returns the parse options of a request. The "chat"
+// parameter records the chat, "all" the whole chat and "filtered" only the
+// messages with a word of the word list
+func (s *server) requestOptions(c *gin.Context) (demostats.Options, error) {
+	opts := s.parseOpts
+	switch c.Query("chat") {
+	case "":
+	case "all":
+		opts.Chat = true
+	case "filtered":
+		if len(s.chatWords) == 0 {
+			return opts, errors.New("no chat word list configured")
+		}
+		opts.Chat = true
+		opts.ChatWords = s.chatWords
+	default:
+		return opts, errors.New("chat must be all or filtered")
+	}
+	return opts, nil
+}
+
+// chatOption is a gin middleware rejecting parse requests with invalid
+// options before they wait for a parse slot, see requestOptions
+func (s *server) chatOption() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := s.requestOptions(c); err != nil {
+			c.AbortWithStatusJSON(400, err.Error())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := &server{}
+	var opts demostats.Options
+	r := gin.New()
+	r.GET("/", srv.chatOption(), func(c *gin.Context) {
+		opts, _ = srv.requestOptions(c)
+		c.Status(200)
+	})
+	get := func(target string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, 200, get("/"))
+	assert.False(t, opts.Chat)
+	assert.Equal(t, 200, get("/?chat=all"))
+	assert.True(t, opts.Chat)
+	assert.Empty(t, opts.ChatWords)
+	assert.Equal(t, 400, get("/?chat=filtered"))
+	assert.Equal(t, 400, get("/?chat=yes"))
+
+	srv.chatWords = []string{"noob"}
+	assert.Equal(t, 200, get("/?chat=filtered"))
+	assert.True(t, opts.Chat)
+	assert.Equal(t, []string{"noob"}, opts.ChatWords)
+}
+
+func TestChatVariant(t *testing.T) {
+	filtered := func(words ...string) demostats.Options {
+		return demostats.Options{Chat: true, ChatWords: words}
+	}
+	assert.Equal(t, "", chatVariant(demostats.Options{}))
+	assert.Equal(t, " chat", chatVariant(demostats.Options{Chat: true}))
+	assert.NotEqual(t, chatVariant(filtered("a")), chatVariant(filtered("b")))
+}
+
+func TestChatWordsFromEnv(t *testing.T) {
+	f, err := ioutil.TempFile("", "demo-stats-words-")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("# insults\nnoob\n\n  trash \n")
+	f.Close()
+
+	os.Setenv("DEMO_STATS_CHAT_WORDS_FILE", f.Name())
+	defer os.Unsetenv("DEMO_STATS_CHAT_WORDS_FILE")
+	words, err := chatWordsFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"noob", "trash"}, words)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+)
+
+const usage = `usage: csgo-demo-stats [command]
+
+Without a command the http and grpc servers are started.
+
+commands:
+  parse-object bucket/key     parse a demo from the object storage
+  ingest-objects bucket/prefix
+                              parse all demos below the prefix that weren't
+                              ingested before, see DEMO_STATS_S3_INGEST_STATE
+  keys create name scopes [rate limit]
+                              create an api key with comma separated scopes
+                              (parse, read, admin) and an optional limit of
+                              requests per minute
+  keys list                   list the api keys
+  keys revoke id              revoke an api key
+
+The scoreboards are written to stdout as JSON, one line per match. Api keys
+are stored in DEMO_STATS_API_KEYS_FILE, a running server picks up changes
+without a restart.
+`
+
+// runCommand runs a command line command and returns the exit code
+func runCommand(args []string) int {
+	if err := command(context.Background(), args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func command(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) > 0 && args[0] == "keys" {
+		return keysCommand(args[1:], out)
+	}
+	if len(args) != 2 {
+		return errors.New(usage)
+	}
+
+	parseOpts, err := parseOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	s3, err := s3ClientFromEnv()
+	if err != nil {
+		return err
+	}
+	if s3 == nil {
+		return errS3NotConfigured
+	}
+
+	enc := json.NewEncoder(out)
+	parse := func(ctx context.Context, path string) error {
+		matchInfo, err := demostats.ParseFile(ctx, path, parseOpts)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(matchInfo.GetScoreboard())
+	}
+
+	switch args[0] {
+	case "parse-object":
+		bucket, key, err := splitObject(args[1])
+		if err != nil {
+			return err
+		}
+		path, err := s3.download(ctx, bucket, key, parseOpts.MaxSize)
+		if err != nil {
+			return err
+		}
+		defer removeDownload(path)
+		return parse(ctx, path)
+	case "ingest-objects":
+		statePath, _ := os.LookupEnv("DEMO_STATS_S3_INGEST_STATE")
+		ingester, err := newS3Ingester(s3, args[1], statePath, parseOpts.MaxSize, parse)
+		if err != nil {
+			return err
+		}
+		_, err = ingester.ingest(ctx)
+		return err
+	default:
+		return errors.New(usage)
+	}
+}
+
+// keysCommand manages the api keys in the key file
+func keysCommand(args []string, out io.Writer) error {
+	path, ok := os.LookupEnv("DEMO_STATS_API_KEYS_FILE")
+	if !ok || path == "" {
+		return errors.New("DEMO_STATS_API_KEYS_FILE is not set")
+	}
+	keys, err := newKeyStore(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(args) >= 3 && len(args) <= 4 && args[0] == "create":
+		rateLimit := 0
+		if len(args) == 4 {
+			if rateLimit, err = strconv.Atoi(args[3]); err != nil {
+				return fmt.Errorf("invalid rate limit: %v", err)
+			}
+		}
+		_, key, err := keys.create(args[1], splitList(args[2]), rateLimit)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, key)
+		return err
+	case len(args) == 1 && args[0] == "list":
+		return json.NewEncoder(out).Encode(keys.list())
+	case len(args) == 2 && args[0] == "revoke":
+		ok, err := keys.revoke(args[1])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("api key not found")
+		}
+		return nil
+	}
+	return errors.New(usage)
+}
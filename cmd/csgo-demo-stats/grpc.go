@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/martig3/csgo-demo-stats/pb"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the DemoStats gRPC service
+type grpcServer struct {
+	pb.UnimplementedDemoStatsServer
+	*server
+}
+
+// newGRPCServer creates a gRPC server with the DemoStats service registered.
+// Requests have to send the same credentials as the http api in the
+// "authorization" or "x-api-key" metadata, granted the parse scope
+func newGRPCServer(auth *authenticator, srv *server) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := grpcAuth(grpcRequestLogger(ctx, info.FullMethod), auth, scopeParse)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := grpcAuth(grpcRequestLogger(ss.Context(), info.FullMethod), auth, scopeParse)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &loggedStream{ss, ctx})
+		}),
+	)
+	pb.RegisterDemoStatsServer(s, &grpcServer{server: srv})
+	return s
+}
+
+// loggedStream is a server stream with a context carrying the request logger
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+// Parse reads the demo file from the client stream while parsing it
+func (s *grpcServer) Parse(stream pb.DemoStats_ParseServer) error {
+	if err := s.limiter.acquire(stream.Context(), true); err != nil {
+		return grpcError(err)
+	}
+	defer s.limiter.release()
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			// Fails once the parser has stopped reading
+			if _, err := pw.Write(req.Chunk); err != nil {
+				return
+			}
+		}
+	}()
+
+	matchInfo, err := s.parse(stream.Context(), pr, s.parseOpts)
+	pr.Close()
+	if err != nil {
+		return grpcError(err)
+	}
+
+	s.notify(matchInfo, "")
+	return stream.SendAndClose(matchProto(matchInfo))
+}
+
+// ParseRemote downloads and parses a demo file from a remote url
+func (s *grpcServer) ParseRemote(ctx context.Context, req *pb.ParseRemoteRequest) (*pb.Match, error) {
+	if req.Url == "" {
+		return nil, status.Error(codes.InvalidArgument, "no url specified")
+	}
+
+	path, err := s.downloadRemote(ctx, req.Url, req.Auth)
+	switch {
+	case err == nil:
+	case errors.Is(err, errRemoteForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errIncompleteDownload):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, demostats.ErrDemoTooLarge):
+		return nil, grpcError(err)
+	default:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer removeDownload(path)
+
+	// The slot is taken once the demo is on disk, slow downloads don't block
+	// parses
+	if err := s.limiter.acquire(ctx, true); err != nil {
+		return nil, grpcError(err)
+	}
+	defer s.limiter.release()
+
+	matchInfo, err := s.parseFile(ctx, path, s.parseOpts)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	s.notify(matchInfo, "")
+	return matchProto(matchInfo), nil
+}
+
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, errQueueFull):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, demostats.ErrInvalidFile),
+		errors.Is(err, demostats.ErrUnsupportedDemoVersion):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, demostats.ErrTruncatedDemo):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, demostats.ErrDemoTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, demostats.ErrParseTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// grpcAuth checks the credentials in the metadata of a grpc call and returns
+// the context with the caller added to the logger
+func grpcAuth(ctx context.Context, auth *authenticator, scope string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	p, err := auth.authenticate(first("authorization"), first("x-api-key"))
+	var limitErr *rateLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		return ctx, status.Error(codes.ResourceExhausted, err.Error())
+	case err != nil:
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	case !p.hasScope(scope):
+		return ctx, status.Error(codes.PermissionDenied, errMissingScope.Error()+" "+scope)
+	}
+	return withLogger(ctx, loggerFrom(ctx).WithField("principal", p.Name)), nil
+}
+
+// matchProto converts a match to its protobuf message
+func matchProto(is *demostats.Match) *pb.Match {
+	out := &pb.Match{
+		SchemaVersion: int32(is.SchemaVersion),
+		MatchId:       is.MatchID,
+		MatchValid:    is.MatchValid,
+		Warnings:      is.Warnings,
+		Errors:        is.Errors,
+		General: &pb.ScoreboardGeneral{
+			Winner:          int32(is.General.Winner),
+			ScoreA:          int32(is.General.ScoreA),
+			ScoreB:          int32(is.General.ScoreB),
+			MapName:         is.General.MapName,
+			MapIconUrl:      is.General.MapIconURL,
+			MatchTime:       is.General.MatchTime.Unix(),
+			MatchDurationNs: int64(is.General.MatchDuration),
+			DemoLinkUrl:     is.General.DemoLinkURL,
+			ServerName:      is.General.ServerName,
+			ClientName:      is.General.ClientName,
+			DemoType:        string(is.General.DemoType),
+			TickRate:        is.General.TickRate,
+			PlaybackTicks:   int32(is.General.PlaybackTicks),
+			PlaybackFrames:  int32(is.General.PlaybackFrames),
+			DemoDurationNs:  int64(is.General.DemoDuration),
+			GameMode:        string(is.General.GameMode),
+			MaxRounds:       int32(is.General.MaxRounds),
+		},
+	}
+
+	for _, ps := range is.General.Halves {
+		out.General.Halves = append(out.General.Halves, periodProto(ps))
+	}
+	for _, ps := range is.General.Overtimes {
+		out.General.Overtimes = append(out.General.Overtimes, periodProto(ps))
+	}
+
+	for _, p := range is.Players.Players {
+		out.Players = append(out.Players, playerProto(p))
+	}
+
+	for _, r := range is.Rounds {
+		out.Rounds = append(out.Rounds, roundProto(r))
+	}
+
+	for _, c := range is.Connections {
+		out.Connections = append(out.Connections, &pb.Connection{
+			Round:     int32(c.Round),
+			TimeNs:    int64(c.Time),
+			Steamid64: c.Steamid64,
+			Name:      c.Name,
+			Connected: c.Connected,
+		})
+	}
+
+	for _, c := range is.Chat {
+		out.Chat = append(out.Chat, &pb.ChatMessage{
+			Round:     int32(c.Round),
+			TimeNs:    int64(c.Time),
+			Steamid64: c.Steamid64,
+			Name:      c.Name,
+			Team:      c.TeamChar,
+			AllChat:   c.AllChat,
+			Text:      c.Text,
+			Matches:   c.Matches,
+		})
+	}
+
+	return out
+}
+
+// periodProto converts the score of a half or an overtime to its protobuf
+// message
+func periodProto(ps demostats.PeriodScore) *pb.PeriodScore {
+	return &pb.PeriodScore{ScoreA: int32(ps.ScoreA), ScoreB: int32(ps.ScoreB)}
+}
+
+// playerProto converts a player to its protobuf message
+func playerProto(sp demostats.ScoreboardPlayer) *pb.ScoreboardPlayer {
+	out := &pb.ScoreboardPlayer{
+		IsBot:            sp.IsBot,
+		IsAMember:        sp.IsAMember,
+		Team:             sp.TeamChar,
+		Steamid:          sp.SteamId,
+		Steamid64:        sp.Steamid64,
+		Name:             sp.Name,
+		Atag:             sp.Atag,
+		Rank:             int32(sp.Rank),
+		Kills:            int32(sp.Kills),
+		Mvps:             int32(sp.MVPs),
+		Deaths:           int32(sp.Deaths),
+		Assists:          int32(sp.Assists),
+		Kd:               sp.Kd,
+		Adr:              sp.Adr,
+		Kast:             sp.Kast,
+		KastRounds:       int32(sp.KastRounds),
+		Rws:              sp.Rws,
+		Rating:           sp.Rating,
+		Kpr:              sp.Kpr,
+		RoundsPlayed:     int32(sp.RoundsPlayed),
+		Substitute:       sp.Substitute,
+		Headshots:        int32(sp.Headshots),
+		Hsprecent:        sp.Hsprecent,
+		Firstkills:       int32(sp.Firstkills),
+		Firstdeaths:      int32(sp.Firstdeaths),
+		Tradekills:       int32(sp.Tradekills),
+		Tradedeaths:      int32(sp.Tradedeaths),
+		Tradefirstkills:  int32(sp.Tradefirstkills),
+		Tradefirstdeaths: int32(sp.Tradefirstdeaths),
+		Roundswonv5:      int32(sp.Roundswonv5),
+		Roundswonv4:      int32(sp.Roundswonv4),
+		Roundswonv3:      int32(sp.Roundswonv3),
+		Roundswonv2:      int32(sp.Roundswonv2),
+		Rounds5K:         int32(sp.Rounds5K),
+		Rounds4K:         int32(sp.Rounds4K),
+		Rounds3K:         int32(sp.Rounds3K),
+		Rounds2K:         int32(sp.Rounds2K),
+		Rounds1K:         int32(sp.Rounds1K),
+		EffFlashes:       int32(sp.EffFlashes),
+		Efpr:             sp.Efpr,
+		FlashDurationMs:  sp.FlashDuration,
+		PlayerDamages:    make(map[uint64]int32),
+	}
+
+	for _, w := range weaponsUsed(sp.WeaponStats) {
+		out.WeaponStats = append(out.WeaponStats, &pb.WeaponStat{
+			Weapon:     int32(w),
+			WeaponName: w.String(),
+			Kills:      int32(sp.WeaponStats.Kills[w]),
+			Headshots:  int32(sp.WeaponStats.Headshots[w]),
+			Accuracy:   int32(sp.WeaponStats.Accuracy[w]),
+			Damage:     int32(sp.WeaponStats.Damage[w]),
+			Shots:      int32(sp.WeaponStats.Shots[w]),
+			Hits:       int32(sp.WeaponStats.Hits[w]),
+		})
+	}
+
+	for k, v := range sp.PlayerDamages.Damages {
+		out.PlayerDamages[k] = int32(v)
+	}
+
+	return out
+}
+
+// roundProto converts a round to its protobuf message
+func roundProto(sr demostats.ScoreboardRound) *pb.ScoreboardRound {
+	out := &pb.ScoreboardRound{
+		AWonRound:        sr.AWonRound,
+		DurationNs:       int64(sr.Duration),
+		ScoreA:           int32(sr.ScoreA),
+		ScoreB:           int32(sr.ScoreB),
+		SurvivorsA:       int32(sr.ASurvivors),
+		SurvivorsB:       int32(sr.BSurvivors),
+		TeamWon:          int32(sr.TeamWon),
+		TotalDamageGiven: int32(sr.TotalDamageGiven),
+		TotalDamageTaken: int32(sr.TotalDamageTaken),
+		WinReason:        int32(sr.WinReason),
+		WinnerTeam:       int32(sr.WinnerTeam),
+		BombPlanter:      sr.BombPlanter,
+		BombDefuser:      sr.BombDefuser,
+		Incomplete:       sr.Incomplete,
+		Half:             int32(sr.Half),
+		Overtime:         int32(sr.Overtime),
+		Knife:            sr.Knife,
+	}
+
+	for _, k := range sr.AKills {
+		out.KillsA = append(out.KillsA, killProto(k))
+	}
+	for _, k := range sr.BKills {
+		out.KillsB = append(out.KillsB, killProto(k))
+	}
+
+	return out
+}
+
+// killProto converts a kill to its protobuf message
+func killProto(rk demostats.RoundKill) *pb.RoundKill {
+	out := &pb.RoundKill{
+		TimeNs:        int64(rk.Time),
+		IsHeadshot:    rk.IsHeadshot,
+		Weapon:        int32(rk.KillerWeapon),
+		WeaponName:    rk.KillerWeapon.String(),
+		BotControlled: rk.BotControlled,
+	}
+	if rk.Killer != nil {
+		out.Killer = rk.Killer.Steamid64
+	}
+	if rk.Victim != nil {
+		out.Victim = rk.Victim.Steamid64
+	}
+	if rk.Assister != nil {
+		out.Assister = rk.Assister.Steamid64
+	}
+	return out
+}
+
+// weaponsUsed returns all weapons the stats have entries for, ordered by
+// equipment type
+func weaponsUsed(ws demostats.WeaponStats) []common.EquipmentType {
+	seen := make(map[common.EquipmentType]bool)
+	for _, stat := range []map[common.EquipmentType]int{ws.Kills, ws.Shots, ws.Damage, ws.Hits} {
+		for w := range stat {
+			seen[w] = true
+		}
+	}
+
+	out := []common.EquipmentType{}
+	for w := range seen {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
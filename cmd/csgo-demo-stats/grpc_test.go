@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/martig3/csgo-demo-stats/pb"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// testAuthenticator returns an authenticator with the basic auth account
+// user:pass and no api keys
+func testAuthenticator(t *testing.T) *authenticator {
+	keys, err := newKeyStore("")
+	assert.NoError(t, err)
+	return &authenticator{keys: keys, basicUser: "user", basicPass: "pass"}
+}
+
+func grpcTestClient(t *testing.T, auth *authenticator) (pb.DemoStatsClient, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	s := newGRPCServer(auth, &server{webhooks: NewWebhookNotifier("", defaultRemotePolicy())})
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	assert.NoError(t, err)
+
+	return pb.NewDemoStatsClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	auth := testAuthenticator(t)
+	_, readKey, err := auth.keys.create("reader", []string{scopeRead}, 0)
+	assert.NoError(t, err)
+	client, done := grpcTestClient(t, auth)
+	defer done()
+
+	_, err = client.ParseRemote(context.Background(), &pb.ParseRemoteRequest{Url: "http://localhost"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", readKey)
+	_, err = client.ParseRemote(ctx, &pb.ParseRemoteRequest{Url: "http://localhost"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPCParseInvalidFile(t *testing.T) {
+	client, done := grpcTestClient(t, testAuthenticator(t))
+	defer done()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic dXNlcjpwYXNz")
+	stream, err := client.Parse(ctx)
+	assert.NoError(t, err)
+	// Large enough to contain a whole demo header
+	assert.NoError(t, stream.Send(&pb.ParseRequest{Chunk: bytes.Repeat([]byte("not a demo"), 1024)}))
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMatchProto(t *testing.T) {
+	a := &demostats.ScoreboardPlayer{Name: "a", Steamid64: 76561197990376443, WeaponStats: demostats.NewWeaponstats()}
+	b := &demostats.ScoreboardPlayer{Name: "b", Steamid64: 76561197971293742}
+	a.WeaponStats.Shots[common.EqAK47] = 10
+	a.WeaponStats.Kills[common.EqAK47] = 1
+
+	m := matchProto(&demostats.Match{
+		Players: demostats.ScoreboardPlayers{Players: []demostats.ScoreboardPlayer{*a, *b}},
+		Rounds: []demostats.ScoreboardRound{{
+			AKills: []demostats.RoundKill{{Killer: a, Victim: b, KillerWeapon: common.EqAK47}},
+			BKills: []demostats.RoundKill{{Killer: b, Victim: a, Assister: b, KillerWeapon: common.EqAWP}},
+		}},
+	})
+
+	assert.Len(t, m.Players, 2)
+	assert.Equal(t, []*pb.WeaponStat{{Weapon: int32(common.EqAK47), WeaponName: "AK-47", Kills: 1, Shots: 10}},
+		m.Players[0].WeaponStats)
+	assert.Equal(t, uint64(76561197990376443), m.Rounds[0].KillsA[0].Killer)
+	assert.Equal(t, uint64(76561197971293742), m.Rounds[0].KillsB[0].Assister)
+}
+
+// fillValue sets every exported field reachable from v to a distinct non zero
+// value, so a field the converters forget shows up as missing
+func fillValue(v reflect.Value, n *int) {
+	*n++
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(*n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(*n))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(*n) + 0.5)
+	case reflect.String:
+		v.SetString(fmt.Sprintf("s%d", *n))
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(v.Elem(), n)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(v.Index(0), n)
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fillValue(key, n)
+		fillValue(elem, n)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			v.Set(reflect.ValueOf(time.Unix(int64(*n), 0).UTC()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				fillValue(v.Field(i), n)
+			}
+		}
+	}
+}
+
+// decodeJSON decodes a json document keeping the numbers as written
+func decodeJSON(t *testing.T, b []byte) interface{} {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out interface{}
+	assert.NoError(t, dec.Decode(&out))
+	return out
+}
+
+// Fields named differently in the http json and the grpc match
+var protoNames = map[string]string{
+	"isbot":          "is_bot",
+	"isamember":      "is_a_member",
+	"kastRounds":     "kast_rounds",
+	"effFlashes":     "eff_flashes",
+	"flashDuration":  "flash_duration_ms",
+	"survivivors_a":  "survivors_a",
+	"duration":       "duration_ns",
+	"time":           "time_ns",
+	"match_duration": "match_duration_ns",
+	"demo_duration":  "demo_duration_ns",
+}
+
+// Fields of the http json that are left out of the grpc match or that have a
+// different shape there, match_time is unix seconds and the weapon stats and
+// damages are flattened
+var protoSkipped = map[string]bool{
+	"rd_damages":           true,
+	"killer_team_string":   true,
+	"victim_team_string":   true,
+	"assister_team_string": true,
+	"match_time":           true,
+	"weapon_stats":         true,
+	"player_damages":       true,
+}
+
+// compareJSON checks that the grpc json holds every field of the http json with
+// the same value
+func compareJSON(t *testing.T, path string, want, got interface{}) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		if id, ok := got.(string); ok {
+			// Players of kills are referenced by their steamid64
+			assert.Equal(t, fmt.Sprint(w["steamid64"]), id, path)
+			return
+		}
+		g, ok := got.(map[string]interface{})
+		if !assert.True(t, ok, path) {
+			return
+		}
+		used := map[string]bool{}
+		for k, v := range w {
+			if protoSkipped[k] {
+				used[k] = true
+				continue
+			}
+			name := k
+			if n, ok := protoNames[k]; ok {
+				name = n
+			}
+			used[name] = true
+			if assert.Contains(t, g, name, path) {
+				compareJSON(t, path+"."+k, v, g[name])
+			}
+		}
+		for k := range g {
+			// The grpc match adds the weapon name to kills
+			assert.True(t, used[k] || k == "weapon_name", "%s.%s not in the http json", path, k)
+		}
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if assert.True(t, ok, path) && assert.Len(t, g, len(w), path) {
+			for i := range w {
+				compareJSON(t, fmt.Sprintf("%s[%d]", path, i), w[i], g[i])
+			}
+		}
+	default:
+		// protojson writes 64 bit integers as strings
+		assert.Equal(t, fmt.Sprint(want), fmt.Sprint(got), path)
+	}
+}
+
+func TestMatchProtoMatchesJSON(t *testing.T) {
+	var is demostats.Match
+	n := 0
+	fillValue(reflect.ValueOf(&is).Elem(), &n)
+
+	b, err := json.Marshal(is)
+	assert.NoError(t, err)
+	want := decodeJSON(t, b).(map[string]interface{})
+	// The http json nests the players in an object
+	want["players"] = want["players"].(map[string]interface{})["players"]
+
+	b, err = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(matchProto(&is))
+	assert.NoError(t, err)
+
+	compareJSON(t, "match", want, decodeJSON(t, b))
+}
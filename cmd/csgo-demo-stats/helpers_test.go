@@ -0,0 +1,16 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// testTempDir creates a temporary directory, the returned func removes it
+func testTempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "csgo-demo-stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// errQueueFull signals that the parse queue is full
+var errQueueFull = errors.New("too many parses queued, try again later")
+
+var parseQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "demo_stats_parse_queue_depth",
+	Help: "Parses waiting for a free parse slot.",
+})
+
+// parseLimiter bounds the number of parses running at once. Further parses
+// wait in a queue of limited length
+type parseLimiter struct {
+	slots      chan struct{}
+	queueLimit int
+	retryAfter time.Duration
+
+	mu     sync.Mutex
+	queued int
+}
+
+// parseStatus is the state of the parse limiter
+type parseStatus struct {
+	Running    int `json:"parses_running"`
+	Limit      int `json:"parse_limit"`
+	Queued     int `json:"parses_queued"`
+	QueueLimit int `json:"queue_limit"`
+}
+
+// newParseLimiter constructor for a parse limiter running at most limit
+// parses with at most queueLimit parses waiting
+func newParseLimiter(limit int, queueLimit int, retryAfter time.Duration) *parseLimiter {
+	return &parseLimiter{
+		slots:      make(chan struct{}, limit),
+		queueLimit: queueLimit,
+		retryAfter: retryAfter,
+	}
+}
+
+// parseLimiterFromEnv creates the parse limiter. Defaults to one parse per
+// cpu and a queue of 10 parses, rejected requests are told to retry after 30
+// seconds
+func parseLimiterFromEnv() (*parseLimiter, error) {
+	limit := runtime.NumCPU()
+	queueLimit := 10
+	retryAfter := time.Second * 30
+
+	if v, ok := os.LookupEnv("DEMO_STATS_MAX_PARSES"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_MAX_PARSES %q", v)
+		}
+		limit = n
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_PARSE_QUEUE"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_PARSE_QUEUE %q", v)
+		}
+		queueLimit = n
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_PARSE_RETRY_AFTER"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEMO_STATS_PARSE_RETRY_AFTER: %v", err)
+		}
+		retryAfter = d
+	}
+	return newParseLimiter(limit, queueLimit, retryAfter), nil
+}
+
+// acquire waits for a free parse slot until ctx is done. Bounded callers get
+// errQueueFull right away if the queue is full, background jobs pass false
+// to wait regardless. A nil limiter doesn't limit
+func (l *parseLimiter) acquire(ctx context.Context, bounded bool) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	if bounded && l.queued >= l.queueLimit {
+		l.mu.Unlock()
+		return errQueueFull
+	}
+	l.queued++
+	parseQueueDepth.Set(float64(l.queued))
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		parseQueueDepth.Set(float64(l.queued))
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a parse slot taken by acquire
+func (l *parseLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// status returns the number of running and queued parses
+func (l *parseLimiter) status() parseStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return parseStatus{
+		Running:    len(l.slots),
+		Limit:      cap(l.slots),
+		Queued:     l.queued,
+		QueueLimit: l.queueLimit,
+	}
+}
+
+// limitParses is a gin middleware holding a parse slot for the request, see
+// acquireParse
+func (s *server) limitParses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.acquireParse(c) {
+			return
+		}
+		defer s.limiter.release()
+		c.Next()
+	}
+}
+
+// acquireParse takes a parse slot for the request, the caller releases it.
+// Requests are rejected with 503 if the queue is full, false is returned
+// if the request was aborted
+func (s *server) acquireParse(c *gin.Context) bool {
+	err := s.limiter.acquire(c.Request.Context(), true)
+	switch {
+	case errors.Is(err, errQueueFull):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(s.limiter.retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(503, parseErrorResponse{Error: "queue_full", Message: err.Error()})
+		return false
+	case err != nil:
+		// The client is gone
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// parseFileQueued waits for a parse slot without a queue limit and parses
+// the file, for background jobs
+func (s *server) parseFileQueued(ctx context.Context, path string) (*demostats.Match, error) {
+	if err := s.limiter.acquire(ctx, false); err != nil {
+		return nil, err
+	}
+	defer s.limiter.release()
+	return s.parseFile(ctx, path, s.parseOpts)
+}
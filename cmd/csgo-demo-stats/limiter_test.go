@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimiter(t *testing.T) {
+	l := newParseLimiter(1, 1, time.Second)
+	assert.NoError(t, l.acquire(context.Background(), true))
+
+	// The second parse waits in the queue, the third is rejected
+	acquired := make(chan error)
+	go func() { acquired <- l.acquire(context.Background(), true) }()
+	assert.Eventually(t, func() bool { return l.status().Queued == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, errQueueFull, l.acquire(context.Background(), true))
+	assert.Equal(t, parseStatus{Running: 1, Limit: 1, Queued: 1, QueueLimit: 1}, l.status())
+
+	// Background jobs wait regardless of the queue limit
+	ctx, cancel := context.WithCancel(context.Background())
+	background := make(chan error)
+	go func() { background <- l.acquire(ctx, false) }()
+	assert.Eventually(t, func() bool { return l.status().Queued == 2 }, time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-background)
+
+	l.release()
+	assert.NoError(t, <-acquired)
+	assert.Equal(t, parseStatus{Running: 1, Limit: 1, Queued: 0, QueueLimit: 1}, l.status())
+	l.release()
+}
+
+func TestLimitParses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := &server{limiter: newParseLimiter(1, 0, 90*time.Second)}
+	release := make(chan struct{})
+	r := gin.New()
+	r.GET("/", srv.limitParses(), func(c *gin.Context) {
+		<-release
+		c.Status(200)
+	})
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		done <- w.Code
+	}()
+	assert.Eventually(t, func() bool { return srv.limiter.status().Running == 1 }, time.Second, time.Millisecond)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, 200, <-done)
+	assert.Equal(t, 0, srv.limiter.status().Running)
+}
+
+func TestParseRemoteSlotAfterDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var downloads int32
+	demo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downloads, 1)
+		w.Write([]byte("demo"))
+	}))
+	defer demo.Close()
+
+	srv := &server{remote: testRemoteClient(), limiter: newParseLimiter(1, 0, time.Second)}
+	r := gin.New()
+	r.GET("/", srv.handleParseRemote(false))
+
+	// The download runs while all slots are taken, the parse is rejected
+	assert.NoError(t, srv.limiter.acquire(context.Background(), true))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/?url="+demo.URL+"/demo.dem", nil))
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downloads))
+
+	srv.limiter.release()
+	assert.Equal(t, 0, srv.limiter.status().Running)
+}
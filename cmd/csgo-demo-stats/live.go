@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	log "github.com/sirupsen/logrus"
+)
+
+// errTooManyBroadcasts signals that the maximum number of broadcasts is
+// already followed
+var errTooManyBroadcasts = errors.New("too many broadcasts followed, try again later")
+
+// liveEvent is a server-sent event of a followed broadcast
+type liveEvent struct {
+	name string
+	data interface{}
+}
+
+// liveMatch is a broadcast that is being parsed. Its events are sent to all
+// subscribers, the parse is cancelled once the last subscriber left
+type liveMatch struct {
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	state       *demostats.Match
+	progress    demostats.Progress
+	subscribers map[chan liveEvent]struct{}
+	final       *liveEvent
+	stopped     bool
+}
+
+// liveHub holds the followed broadcasts by url, every broadcast is parsed
+// once no matter how many clients follow it
+type liveHub struct {
+	limit       int
+	timeout     time.Duration
+	maxDuration time.Duration
+
+	mu      sync.Mutex
+	matches map[string]*liveMatch
+}
+
+// newLiveHub constructor for a hub following at most limit broadcasts. A
+// broadcast ends after timeout without a new fragment and is parsed for at
+// most maxDuration
+func newLiveHub(limit int, timeout time.Duration, maxDuration time.Duration) *liveHub {
+	return &liveHub{limit: limit, timeout: timeout, maxDuration: maxDuration, matches: map[string]*liveMatch{}}
+}
+
+// liveHubFromEnv creates the live hub. It follows at most 10 broadcasts at
+// once by default, broadcasts end after a minute without a new fragment or
+// after 4 hours
+func liveHubFromEnv() (*liveHub, error) {
+	limit := 10
+	timeout := time.Minute
+	maxDuration := time.Hour * 4
+	if v, ok := os.LookupEnv("DEMO_STATS_MAX_BROADCASTS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_MAX_BROADCASTS %q", v)
+		}
+		limit = n
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_BROADCAST_TIMEOUT"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEMO_STATS_BROADCAST_TIMEOUT: %v", err)
+		}
+		timeout = d
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_BROADCAST_MAX_DURATION"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid DEMO_STATS_BROADCAST_MAX_DURATION %q", v)
+		}
+		maxDuration = d
+	}
+	return newLiveHub(limit, timeout, maxDuration), nil
+}
+
+// get returns the live match of the broadcast url or nil if it isn't
+// followed
+func (h *liveHub) get(rawURL string) *liveMatch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.matches[rawURL]
+}
+
+// follow returns the live match of the broadcast url. Broadcasts that
+// aren't followed yet are parsed by parse in the background until ctx is
+// done. A broadcast whose parse was stopped for lack of subscribers is
+// followed again
+func (h *liveHub) follow(rawURL string, parse func(ctx context.Context, lm *liveMatch) *liveEvent) (*liveMatch, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	lm, ok := h.matches[rawURL]
+	if ok && !lm.isStopped() {
+		return lm, nil
+	}
+	if !ok && len(h.matches) >= h.limit {
+		return nil, errTooManyBroadcasts
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lm = &liveMatch{cancel: cancel, subscribers: map[chan liveEvent]struct{}{}}
+	h.matches[rawURL] = lm
+	go func() {
+		defer cancel()
+		final := parse(ctx, lm)
+		h.mu.Lock()
+		if h.matches[rawURL] == lm {
+			delete(h.matches, rawURL)
+		}
+		h.mu.Unlock()
+		lm.finish(final)
+	}()
+	return lm, nil
+}
+
+// subscribe returns a channel receiving the events of the match, starting
+// with the current state. The channel is closed once the parse finished,
+// see result. The returned func unsubscribes, the parse is stopped when the
+// last subscriber left
+func (lm *liveMatch) subscribe() (chan liveEvent, func()) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	events := make(chan liveEvent, 16)
+	if lm.final != nil {
+		close(events)
+		return events, func() {}
+	}
+	if lm.state != nil {
+		events <- liveEvent{"state", lm.state}
+	}
+	events <- liveEvent{"progress", lm.progress}
+	lm.subscribers[events] = struct{}{}
+
+	return events, func() {
+		lm.mu.Lock()
+		defer lm.mu.Unlock()
+		delete(lm.subscribers, events)
+		if len(lm.subscribers) == 0 && lm.final == nil && !lm.stopped {
+			lm.stopped = true
+			lm.cancel()
+		}
+	}
+}
+
+// isStopped reports whether the parse was cancelled because the last
+// subscriber left
+func (lm *liveMatch) isStopped() bool {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.stopped
+}
+
+// publish sends the event to all subscribers. Subscribers that can't keep
+// up miss the event
+func (lm *liveMatch) publish(ev liveEvent) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	for events := range lm.subscribers {
+		select {
+		case events <- ev:
+		default:
+		}
+	}
+}
+
+// setProgress records and publishes the progress of the parse
+func (lm *liveMatch) setProgress(progress demostats.Progress) {
+	lm.mu.Lock()
+	lm.progress = progress
+	lm.mu.Unlock()
+	lm.publish(liveEvent{"progress", progress})
+}
+
+// setState records the match after a round and publishes the round and the
+// match
+func (lm *liveMatch) setState(m *demostats.Match) {
+	lm.mu.Lock()
+	lm.state = m
+	lm.mu.Unlock()
+	if len(m.Rounds) > 0 {
+		lm.publish(liveEvent{"round", m.Rounds[len(m.Rounds)-1]})
+	}
+	lm.publish(liveEvent{"state", m})
+}
+
+// current returns the match after the last round, nil before the first
+// round ended
+func (lm *liveMatch) current() *demostats.Match {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.state
+}
+
+// finish closes the channels of all subscribers, they get the final event
+// from result
+func (lm *liveMatch) finish(final *liveEvent) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	lm.final = final
+	for events := range lm.subscribers {
+		close(events)
+	}
+	lm.subscribers = nil
+}
+
+// result returns the final event of a finished parse
+func (lm *liveMatch) result() *liveEvent {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.final
+}
+
+// parseBroadcast parses the broadcast for the live match until ctx is done
+// and returns the final event. The parse holds a parse slot like any other,
+// the finished match is sent to the webhooks
+func (s *server) parseBroadcast(ctx context.Context, rawURL string, lm *liveMatch) *liveEvent {
+	ctx = withLogger(ctx, log.WithField("broadcast", rawURL))
+	opts := s.parseOpts
+	// The parse timeout of demo files would end broadcasts early, they last
+	// as long as the match
+	opts.MaxDuration = s.live.maxDuration
+	opts.Logger = loggerFrom(ctx)
+	opts.Progress = lm.setProgress
+	opts.RoundEnd = lm.setState
+
+	matchInfo, err := s.followBroadcast(ctx, rawURL, opts)
+	switch {
+	case errors.Is(err, context.Canceled):
+		loggerFrom(ctx).Info("broadcast unfollowed, no subscribers left")
+		return &liveEvent{"error", parseErrorResponse{Error: "canceled", Message: err.Error()}}
+	case err != nil:
+		loggerFrom(ctx).Warning("broadcast parse failed: ", err)
+		_, kind := parseErrorStatus(err)
+		return &liveEvent{"error", parseErrorResponse{Error: kind, Message: err.Error()}}
+	}
+	loggerFrom(ctx).Info("broadcast ended")
+	s.notify(matchInfo, "")
+	return &liveEvent{"result", matchInfo}
+}
+
+// followBroadcast waits for a parse slot and parses the broadcast
+func (s *server) followBroadcast(ctx context.Context, rawURL string, opts demostats.Options) (*demostats.Match, error) {
+	if err := s.limiter.acquire(ctx, true); err != nil {
+		return nil, err
+	}
+	defer s.limiter.release()
+
+	b := demostats.Broadcast{URL: rawURL, Client: s.remote.client, Timeout: s.live.timeout}
+	loggerFrom(ctx).Info("following broadcast")
+	return observeParse(func() int64 { return 0 }, func() (*demostats.Match, error) {
+		return demostats.ParseBroadcast(ctx, b, opts)
+	})
+}
+
+// handleLive returns the match of a followed broadcast after its last
+// round
+func (s *server) handleLive(c *gin.Context) {
+	lm := s.live.get(c.Query("url"))
+	if lm == nil {
+		c.JSON(404, "broadcast is not followed")
+		return
+	}
+	m := lm.current()
+	if m == nil {
+		c.JSON(404, "no round finished yet")
+		return
+	}
+	c.JSON(200, m)
+}
+
+// handleLiveStream follows the broadcast of the "url" parameter and sends
+// its progress, rounds and state as server-sent events until it ends
+func (s *server) handleLiveStream(c *gin.Context) {
+	rawURL := c.Query("url")
+	u, err := url.Parse(rawURL)
+	if rawURL == "" || err != nil {
+		c.JSON(400, "invalid broadcast url")
+		return
+	}
+	if err := s.remote.policy.checkURL(u); err != nil {
+		c.JSON(403, err.Error())
+		return
+	}
+
+	lm, err := s.live.follow(rawURL, func(ctx context.Context, lm *liveMatch) *liveEvent {
+		return s.parseBroadcast(ctx, rawURL, lm)
+	})
+	if err != nil {
+		c.JSON(503, parseErrorResponse{Error: "too_many_broadcasts", Message: err.Error()})
+		return
+	}
+
+	events, unsubscribe := lm.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				final := lm.result()
+				c.SSEvent(final.name, final.data)
+				return
+			}
+			c.SSEvent(ev.name, ev.data)
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
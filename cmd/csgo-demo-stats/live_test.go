@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+// broadcastRelayStub serves the recorded fragments of a short broadcast
+func broadcastRelayStub(syncs *int32) *httptest.Server {
+	const synctick, stop = 3, 7
+	fragments := map[string][]byte{
+		"/match/1/start": {synctick, 1, 0, 0, 0, 0},
+		"/match/5/full":  {synctick, 2, 0, 0, 0, 0},
+		"/match/5/delta": {stop, 3, 0, 0, 0, 0},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/match/sync" {
+			atomic.AddInt32(syncs, 1)
+			w.Write([]byte(`{"fragment":5,"signup_fragment":1,"map":"de_dust2"}`))
+			return
+		}
+		b, ok := fragments[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	}))
+}
+
+func TestLiveStream(t *testing.T) {
+	var syncs int32
+	relay := broadcastRelayStub(&syncs)
+	defer relay.Close()
+
+	gin.SetMode(gin.TestMode)
+	srv := &server{
+		remote:   testRemoteClient(),
+		live:     newLiveHub(1, time.Millisecond*50, time.Minute),
+		webhooks: NewWebhookNotifier("", defaultRemotePolicy()),
+	}
+	r := gin.New()
+	r.GET("/live", srv.handleLive)
+	r.GET("/live/stream", srv.handleLiveStream)
+	target := "/live/stream?url=" + url.QueryEscape(relay.URL+"/match")
+
+	// Both clients follow the same parse
+	done := make(chan string)
+	for i := 0; i < 2; i++ {
+		go func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			done <- w.Body.String()
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		body := <-done
+		assert.Contains(t, body, "event:progress\n")
+		assert.Contains(t, body, fmt.Sprintf("event:result\ndata:{\"schema_version\":%d", demostats.SchemaVersion))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&syncs))
+
+	// The broadcast ended
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/live?url="+url.QueryEscape(relay.URL+"/match"), nil))
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestLiveStreamForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := &server{remote: newRemoteClient(defaultRemotePolicy()), live: newLiveHub(1, time.Second, time.Minute)}
+	r := gin.New()
+	r.GET("/live/stream", srv.handleLiveStream)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/live/stream?url=file:///etc/passwd", nil))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestLiveMatchStopsWithoutSubscribers(t *testing.T) {
+	h := newLiveHub(1, time.Second, time.Minute)
+	stopped := make(chan struct{})
+	parse := func(ctx context.Context, lm *liveMatch) *liveEvent {
+		<-ctx.Done()
+		close(stopped)
+		return &liveEvent{"error", nil}
+	}
+	lm, err := h.follow("broadcast", parse)
+	assert.NoError(t, err)
+
+	_, unsubscribeA := lm.subscribe()
+	_, unsubscribeB := lm.subscribe()
+	unsubscribeA()
+	select {
+	case <-stopped:
+		t.Fatal("parse stopped with a subscriber left")
+	case <-time.After(time.Millisecond * 10):
+	}
+	unsubscribeB()
+	<-stopped
+
+	// A new subscriber starts a new parse
+	next, err := h.follow("broadcast", func(ctx context.Context, lm *liveMatch) *liveEvent {
+		return &liveEvent{"result", nil}
+	})
+	assert.NoError(t, err)
+	assert.True(t, next != lm)
+}
+
+func TestLiveStreamQueueFull(t *testing.T) {
+	var syncs int32
+	relay := broadcastRelayStub(&syncs)
+	defer relay.Close()
+
+	gin.SetMode(gin.TestMode)
+	srv := &server{
+		remote:  testRemoteClient(),
+		limiter: newParseLimiter(1, 0, time.Second),
+		live:    newLiveHub(1, time.Millisecond*50, time.Minute),
+	}
+	r := gin.New()
+	r.GET("/live/stream", srv.handleLiveStream)
+
+	assert.NoError(t, srv.limiter.acquire(context.Background(), true))
+	defer srv.limiter.release()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/live/stream?url="+url.QueryEscape(relay.URL+"/match"), nil))
+	assert.Contains(t, w.Body.String(), "event:error\ndata:{\"error\":\"queue_full\"")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&syncs))
+}
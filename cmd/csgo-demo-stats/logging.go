@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader is the header carrying the id of a request. Ids sent by
+// the caller are kept, otherwise a new one is generated
+const requestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+// withLogger returns a context carrying the logger
+func withLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// loggerFrom returns the logger of the context, the standard logger if there
+// is none
+func loggerFrom(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// requestID returns the id sent by the caller if it is usable, a new random
+// id otherwise
+func requestID(id string) string {
+	if id != "" && len(id) <= 128 {
+		usable := true
+		for _, c := range id {
+			if c < 0x21 || c > 0x7e {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			return id
+		}
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestLogger is a gin middleware attaching a logger with the request id
+// to the request context and logging every request
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestID(c.GetHeader(requestIDHeader))
+		c.Header(requestIDHeader, id)
+		entry := log.WithField("request_id", id)
+		c.Request = c.Request.WithContext(withLogger(c.Request.Context(), entry))
+
+		start := time.Now()
+		c.Next()
+		entry.WithFields(log.Fields{
+			"method":   c.Request.Method,
+			"path":     c.Request.URL.Path,
+			"status":   c.Writer.Status(),
+			"duration": time.Since(start).Seconds(),
+			"client":   c.ClientIP(),
+		}).Info("request")
+	}
+}
+
+// grpcRequestLogger attaches a logger with the request id from the
+// x-request-id metadata to the context of a grpc call and sends the id back
+// in the header
+func grpcRequestLogger(ctx context.Context, method string) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	id = requestID(id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+	return withLogger(ctx, log.WithFields(log.Fields{"request_id": id, "method": method}))
+}
+
+// configureLogging sets the log format and level from the environment. Logs
+// are JSON by default, DEMO_STATS_LOG_FORMAT=text switches to text
+func configureLogging() error {
+	format, _ := os.LookupEnv("DEMO_STATS_LOG_FORMAT")
+	switch format {
+	case "", "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	case "text":
+		log.SetFormatter(&log.TextFormatter{})
+	default:
+		return fmt.Errorf("invalid DEMO_STATS_LOG_FORMAT %q", format)
+	}
+
+	if v, ok := os.LookupEnv("DEMO_STATS_LOG_LEVEL"); ok {
+		level, err := log.ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("invalid DEMO_STATS_LOG_LEVEL: %v", err)
+		}
+		log.SetLevel(level)
+	}
+	return nil
+}
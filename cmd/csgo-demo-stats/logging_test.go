@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "abc-123", requestID("abc-123"))
+	assert.Len(t, requestID(""), 32)
+	assert.Len(t, requestID("with space"), 32)
+	assert.Len(t, requestID(strings.Repeat("a", 129)), 32)
+	assert.NotEqual(t, requestID(""), requestID(""))
+}
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestLogger())
+	r.GET("/", func(c *gin.Context) {
+		c.String(200, "%v", loggerFrom(c.Request.Context()).Data["request_id"])
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "abc-123", w.Header().Get(requestIDHeader))
+	assert.Equal(t, "abc-123", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, w.Header().Get(requestIDHeader), 32)
+	assert.Equal(t, w.Header().Get(requestIDHeader), w.Body.String())
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+func main() {
+	if err := configureLogging(); err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	r := gin.New()
+	r.Use(requestLogger(), gin.Recovery())
+	webhookSecret, _ := os.LookupEnv("DEMO_STATS_WEBHOOK_SECRET")
+	discordWebhook, _ := os.LookupEnv("DEMO_STATS_DISCORD_WEBHOOK")
+	parseOpts, err := parseOptionsFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy, err := remotePolicyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s3, err := s3ClientFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	limiter, err := parseLimiterFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cache, err := resultCacheFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	live, err := liveHubFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	chatWords, err := chatWordsFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &server{
+		parseOpts:      parseOpts,
+		remote:         newRemoteClient(policy),
+		s3:             s3,
+		limiter:        limiter,
+		cache:          cache,
+		live:           live,
+		chatWords:      chatWords,
+		webhooks:       NewWebhookNotifier(webhookSecret, webhookPolicy(policy)),
+		discordWebhook: discordWebhook,
+	}
+	if location, ok := os.LookupEnv("DEMO_STATS_S3_INGEST"); ok {
+		ingester, interval, err := srv.ingesterFromEnv(location)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go ingester.run(context.Background(), interval)
+	}
+	if dir, ok := os.LookupEnv("DEMO_STATS_WATCH_DIR"); ok {
+		watcher, interval, err := srv.watcherFromEnv(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go watcher.run(context.Background(), interval)
+	}
+	auth, err := authenticatorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	api := r.Group("/api")
+	api.POST("/parse", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.limitParses(), srv.handleParse(false))
+	api.POST("/parse/stream", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.limitParses(), srv.handleParse(true))
+	api.GET("/parse-remote", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.handleParseRemote(false))
+	api.GET("/parse-remote/stream", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.handleParseRemote(true))
+	api.GET("/parse-object", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.handleParseObject(false))
+	api.GET("/parse-object/stream", auth.require(scopeParse), srv.chatOption(), outputOption(), srv.handleParseObject(true))
+	api.GET("/live", auth.require(scopeRead), srv.handleLive)
+	api.GET("/live/stream", auth.require(scopeParse), srv.handleLiveStream)
+	api.GET("/status", auth.require(scopeRead), func(c *gin.Context) {
+		c.JSON(200, srv.limiter.status())
+	})
+	api.GET("/webhooks", auth.require(scopeRead), func(c *gin.Context) {
+		c.JSON(200, srv.webhooks.List())
+	})
+	api.POST("/webhooks", auth.require(scopeAdmin), func(c *gin.Context) {
+		var body struct {
+			URL string `json:"url"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		hook, err := srv.webhooks.Register(body.URL)
+		if err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		c.JSON(201, hook)
+	})
+	api.DELETE("/webhooks/:id", auth.require(scopeAdmin), func(c *gin.Context) {
+		if !srv.webhooks.Remove(c.Param("id")) {
+			c.JSON(404, "webhook not found")
+			return
+		}
+		c.Status(204)
+	})
+	registerKeyRoutes(api, auth)
+	grpcPort, ok := os.LookupEnv("DEMO_STATS_GRPC_PORT")
+	if !ok {
+		grpcPort = "9090"
+	}
+	lis, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	grpcServer := newGRPCServer(auth, srv)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Error("grpc server stopped: ", err)
+		}
+	}()
+	if err := r.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// server holds the configuration and clients shared by the http and grpc
+// handlers
+type server struct {
+	parseOpts      demostats.Options
+	remote         *remoteClient
+	s3             *s3Client
+	limiter        *parseLimiter
+	cache          *resultCache
+	live           *liveHub
+	chatWords      []string
+	webhooks       *WebhookNotifier
+	discordWebhook string
+}
+
+// handleParse parses the demo in the request body. stream sends the
+// progress as server-sent events, see streamResult
+func (s *server) handleParse(stream bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil {
+			c.JSON(400, "empty request body")
+			return
+		}
+		if s.parseOpts.MaxSize > 0 && c.Request.ContentLength > s.parseOpts.MaxSize {
+			c.JSON(413, demostats.ErrDemoTooLarge.Error())
+			return
+		}
+		callbackURL := c.Query("callback_url")
+		if callbackURL != "" {
+			if err := s.webhooks.validateCallbackURL(callbackURL); err != nil {
+				c.JSON(400, err.Error())
+				return
+			}
+		}
+		force, _ := strconv.ParseBool(c.Query("force"))
+		// Validated by chatOption
+		opts, _ := s.requestOptions(c)
+		s.respond(c, stream, callbackURL, opts, func(ctx context.Context, opts demostats.Options) (*demostats.Match, error) {
+			return s.parseUpload(ctx, c.Request.Body, force, opts)
+		})
+	}
+}
+
+// handleParseRemote downloads and parses the demo of the "url" parameter
+func (s *server) handleParseRemote(stream bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		url := c.Query("url")
+		authStr := c.Query("auth")
+		if url == "" {
+			c.JSON(400, "no url specified")
+			return
+		}
+		callbackURL := c.Query("callback_url")
+		if callbackURL != "" {
+			if err := s.webhooks.validateCallbackURL(callbackURL); err != nil {
+				c.JSON(400, err.Error())
+				return
+			}
+		}
+		force, _ := strconv.ParseBool(c.Query("force"))
+		// Validated by chatOption
+		opts, _ := s.requestOptions(c)
+		validator, cached := s.cachedRemote(c.Request.Context(), url, authStr, force, opts)
+		var path string
+		if cached == nil {
+			var err error
+			path, err = s.downloadRemote(c.Request.Context(), url, authStr)
+			if err != nil {
+				writeRemoteError(c, err)
+				return
+			}
+			defer removeDownload(path)
+
+			// The slot is taken once the demo is on disk, slow downloads
+			// don't block parses
+			if !s.acquireParse(c) {
+				return
+			}
+			defer s.limiter.release()
+		}
+		s.respond(c, stream, callbackURL, opts, func(ctx context.Context, opts demostats.Options) (*demostats.Match, error) {
+			if cached != nil {
+				return cached, nil
+			}
+			matchInfo, err := s.parseFileCached(ctx, path, force, opts)
+			if err == nil {
+				s.cacheRemote(ctx, url, validator, opts, matchInfo)
+			}
+			return matchInfo, err
+		})
+	}
+}
+
+// handleParseObject downloads and parses the demo of the "object" parameter
+// from the object storage
+func (s *server) handleParseObject(stream bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bucket, key, err := splitObject(c.Query("object"))
+		if err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		callbackURL := c.Query("callback_url")
+		if callbackURL != "" {
+			if err := s.webhooks.validateCallbackURL(callbackURL); err != nil {
+				c.JSON(400, err.Error())
+				return
+			}
+		}
+		path, err := s.downloadObject(c.Request.Context(), bucket, key)
+		if err != nil {
+			writeObjectError(c, err)
+			return
+		}
+		defer removeDownload(path)
+		if !s.acquireParse(c) {
+			return
+		}
+		defer s.limiter.release()
+		force, _ := strconv.ParseBool(c.Query("force"))
+		// Validated by chatOption
+		opts, _ := s.requestOptions(c)
+		s.respond(c, stream, callbackURL, opts, func(ctx context.Context, opts demostats.Options) (*demostats.Match, error) {
+			return s.parseFileCached(ctx, path, force, opts)
+		})
+	}
+}
+
+// notify sends the parsed match to the webhooks and the optional callback
+// url of the request
+func (s *server) notify(matchInfo *demostats.Match, callbackURL string) {
+	s.webhooks.Notify(matchInfo.Summary(), callbackURL)
+	if s.discordWebhook != "" {
+		s.webhooks.NotifyDiscord(s.discordWebhook, matchInfo)
+	}
+}
+
+// downloadRemote downloads a demo from a remote url, see
+// remoteClient.download
+func (s *server) downloadRemote(ctx context.Context, url string, auth string) (string, error) {
+	start := time.Now()
+	path, err := s.remote.download(ctx, url, auth, s.parseOpts.MaxSize)
+	observeDownload("url", start, err)
+	return path, err
+}
+
+// downloadObject downloads a demo from the object storage, see
+// s3Client.download
+func (s *server) downloadObject(ctx context.Context, bucket string, key string) (string, error) {
+	if s.s3 == nil {
+		return "", errS3NotConfigured
+	}
+	start := time.Now()
+	path, err := s.s3.download(ctx, bucket, key, s.parseOpts.MaxSize)
+	observeDownload("object", start, err)
+	return path, err
+}
+
+// ingesterFromEnv creates the object storage ingester of the "bucket/prefix"
+// location. The interval defaults to 5 minutes
+func (s *server) ingesterFromEnv(location string) (*s3Ingester, time.Duration, error) {
+	if s.s3 == nil {
+		return nil, 0, errS3NotConfigured
+	}
+	interval := time.Minute * 5
+	if v, ok := os.LookupEnv("DEMO_STATS_S3_INGEST_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid DEMO_STATS_S3_INGEST_INTERVAL: %v", err)
+		}
+		interval = d
+	}
+
+	statePath, _ := os.LookupEnv("DEMO_STATS_S3_INGEST_STATE")
+	ingester, err := newS3Ingester(s.s3, location, statePath, s.parseOpts.MaxSize, func(ctx context.Context, path string) error {
+		matchInfo, err := s.parseFileQueued(ctx, path)
+		if err != nil {
+			return err
+		}
+		s.notify(matchInfo, "")
+		return nil
+	})
+	return ingester, interval, err
+}
+
+// parseOptionsFromEnv reads the parse limits from the environment. The
+// maximum parse duration defaults to 10 minutes, the demo size is unlimited
+// by default
+func parseOptionsFromEnv() (demostats.Options, error) {
+	opts := demostats.Options{
+		MaxDuration: time.Minute * 10,
+	}
+
+	if v, ok := os.LookupEnv("DEMO_STATS_MAX_PARSE_DURATION"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid DEMO_STATS_MAX_PARSE_DURATION: %v", err)
+		}
+		opts.MaxDuration = d
+	}
+
+	if v, ok := os.LookupEnv("DEMO_STATS_MAX_DEMO_SIZE"); ok {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("invalid DEMO_STATS_MAX_DEMO_SIZE: %v", err)
+		}
+		opts.MaxSize = size
+	}
+
+	return opts, nil
+}
+
+// parseErrorResponse is the body of a failed parse
+type parseErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// writeParseError writes the error of a failed parse with a matching status
+// code
+func writeParseError(c *gin.Context, err error) {
+	if errors.Is(err, context.Canceled) {
+		// The client is gone, nobody reads the response
+		loggerFrom(c.Request.Context()).Info("parse cancelled by client")
+		c.Abort()
+		return
+	}
+
+	status, kind := parseErrorStatus(err)
+	c.JSON(status, parseErrorResponse{Error: kind, Message: err.Error()})
+}
+
+// parseErrorStatus returns the http status code and the error kind of a
+// failed parse
+func parseErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, demostats.ErrInvalidFile):
+		return 400, "invalid_file"
+	case errors.Is(err, demostats.ErrDemoTooLarge):
+		return 413, "demo_too_large"
+	case errors.Is(err, demostats.ErrUnsupportedDemoVersion):
+		return 415, "unsupported_demo_version"
+	case errors.Is(err, demostats.ErrTruncatedDemo):
+		return 422, "truncated_demo"
+	case errors.Is(err, demostats.ErrParseTimeout):
+		return 504, "parse_timeout"
+	case errors.Is(err, demostats.ErrBroadcastUnavailable):
+		return 502, "broadcast_unavailable"
+	case errors.Is(err, errQueueFull):
+		return 503, "queue_full"
+	default:
+		return 500, "internal"
+	}
+}
+
+// writeRemoteError writes the error of a failed remote download
+func writeRemoteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	case errors.Is(err, errRemoteForbidden):
+		c.JSON(403, err.Error())
+	case errors.Is(err, demostats.ErrDemoTooLarge):
+		c.JSON(413, err.Error())
+	case errors.Is(err, errIncompleteDownload):
+		c.JSON(502, err.Error())
+	default:
+		c.JSON(400, err.Error())
+	}
+}
+
+// writeObjectError writes the error of a failed object storage download
+func writeObjectError(c *gin.Context, err error) {
+	var s3Err *s3Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	case errors.Is(err, errS3NotConfigured):
+		c.JSON(501, err.Error())
+	case errors.Is(err, demostats.ErrDemoTooLarge):
+		c.JSON(413, err.Error())
+	case errors.As(err, &s3Err) && (s3Err.Status == 403 || s3Err.Status == 404):
+		c.JSON(s3Err.Status, err.Error())
+	default:
+		c.JSON(502, err.Error())
+	}
+}
+
+// xlsxContentType is the mime type of xlsx workbooks
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// requestedFormat returns the "format" parameter or, without it, the format
+// negotiated from the Accept header
+func requestedFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+	switch c.NegotiateFormat("application/json", "text/csv", xlsxContentType) {
+	case "text/csv":
+		return "csv"
+	case xlsxContentType:
+		return "xlsx"
+	}
+	return "json"
+}
+
+// outputOption is a gin middleware rejecting unknown "format" and "table"
+// parameters before a parse slot is taken, see writeResult
+func outputOption() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch requestedFormat(c) {
+		case "json", "discord", "chat", "xlsx":
+		case "csv":
+			table := c.DefaultQuery("table", demostats.ExportPlayers)
+			if _, err := (demostats.InfoStruct{}).Records(table); err != nil {
+				c.AbortWithStatusJSON(400, err.Error())
+			}
+		default:
+			c.AbortWithStatusJSON(400, "format must be json, discord, chat, csv or xlsx")
+		}
+	}
+}
+
+// writeResult writes the parsed match in the format requested by the
+// "format" query parameter or the Accept header. Defaults to the JSON
+// scoreboard
+func writeResult(c *gin.Context, matchInfo *demostats.Match) {
+	// Partial results of truncated demos are not valid
+	c.Header("X-Demo-Stats-Match-Valid", strconv.FormatBool(matchInfo.MatchValid))
+
+	format := requestedFormat(c)
+	switch format {
+	case "discord":
+		c.JSON(200, matchInfo.DiscordMessage())
+	case "chat":
+		chat := matchInfo.Chat
+		if chat == nil {
+			chat = []demostats.ChatMessage{}
+		}
+		c.JSON(200, chat)
+	case "csv":
+		table := c.DefaultQuery("table", demostats.ExportPlayers)
+		if _, err := matchInfo.Records(table); err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+table+".csv")
+		c.Header("Content-Type", "text/csv")
+		c.Status(200)
+		if err := matchInfo.WriteCSV(c.Writer, table); err != nil {
+			_ = c.Error(err)
+		}
+	case "xlsx":
+		c.Header("Content-Disposition", "attachment; filename=match.xlsx")
+		c.Header("Content-Type", xlsxContentType)
+		c.Status(200)
+		if err := matchInfo.WriteXLSX(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+	case "json":
+		scoreboard := matchInfo.GetScoreboard()
+		c.JSON(200, scoreboard)
+	default:
+		c.JSON(400, "format must be json, discord, chat, csv or xlsx")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// No parse slots, requests passing the check are rejected as queue_full
+	srv := &server{limiter: newParseLimiter(0, 0, time.Second)}
+	r := gin.New()
+	r.GET("/", outputOption(), srv.limitParses(), func(c *gin.Context) {
+		c.Status(200)
+	})
+	get := func(target, accept string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Accept", accept)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, 503, get("/", ""))
+	assert.Equal(t, 503, get("/?format=json", ""))
+	assert.Equal(t, 503, get("/?format=csv&table=kills", ""))
+	assert.Equal(t, 503, get("/", "text/csv"))
+	assert.Equal(t, 400, get("/?format=yaml", ""))
+	assert.Equal(t, 400, get("/?format=csv&table=weapons", ""))
+	assert.Equal(t, 400, get("/?table=weapons", "text/csv"))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	parseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "demo_stats_parse_duration_seconds",
+		Help:    "Time spent parsing demos by result.",
+		Buckets: []float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300, 600},
+	}, []string{"result"})
+
+	demoSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "demo_stats_demo_size_bytes",
+		Help:    "Size of the parsed demos.",
+		Buckets: prometheus.ExponentialBuckets(16<<20, 2, 7),
+	})
+
+	parsesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "demo_stats_parses_total",
+		Help: "Finished parses by result, the error kind for failed parses.",
+	}, []string{"result"})
+
+	parsesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "demo_stats_parses_in_flight",
+		Help: "Parses currently running.",
+	})
+
+	downloadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "demo_stats_remote_download_duration_seconds",
+		Help:    "Time spent downloading demos by source and result.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"source", "result"})
+
+	parserProblems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "demo_stats_parser_problems_total",
+		Help: "Warnings and errors collected by the parser for bad data in demos.",
+	}, []string{"severity"})
+)
+
+// observeParse runs the parse and records its duration, result, the demo
+// size and the problems found in the demo
+func observeParse(size func() int64, parse func() (*demostats.Match, error)) (*demostats.Match, error) {
+	parsesInFlight.Inc()
+	defer parsesInFlight.Dec()
+
+	start := time.Now()
+	matchInfo, err := parse()
+
+	result := "success"
+	if errors.Is(err, context.Canceled) {
+		result = "cancelled"
+	} else if err != nil {
+		_, result = parseErrorStatus(err)
+	}
+	parsesTotal.WithLabelValues(result).Inc()
+	parseDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	if n := size(); n > 0 {
+		demoSize.Observe(float64(n))
+	}
+	if matchInfo != nil {
+		parserProblems.WithLabelValues("warning").Add(float64(len(matchInfo.Warnings)))
+		parserProblems.WithLabelValues("error").Add(float64(len(matchInfo.Errors)))
+	}
+	return matchInfo, err
+}
+
+// observeDownload records the duration of a download from the source
+// started at start
+func observeDownload(source string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	downloadDuration.WithLabelValues(source, result).Observe(time.Since(start).Seconds())
+}
+
+// countingReader counts the bytes read
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(b []byte) (int, error) {
+	n, err := cr.r.Read(b)
+	cr.n += int64(n)
+	return n, err
+}
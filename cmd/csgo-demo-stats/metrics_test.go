@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveParse(t *testing.T) {
+	srv := &server{}
+	invalid := testutil.ToFloat64(parsesTotal.WithLabelValues("invalid_file"))
+	_, err := srv.parse(context.Background(), bytes.NewReader(bytes.Repeat([]byte("not a demo"), 200)), demostats.Options{})
+	assert.Error(t, err)
+	assert.Equal(t, invalid+1, testutil.ToFloat64(parsesTotal.WithLabelValues("invalid_file")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(parsesInFlight))
+
+	success := testutil.ToFloat64(parsesTotal.WithLabelValues("success"))
+	warnings := testutil.ToFloat64(parserProblems.WithLabelValues("warning"))
+	observeParse(func() int64 { return 1 << 20 }, func() (*demostats.Match, error) {
+		assert.Equal(t, float64(1), testutil.ToFloat64(parsesInFlight))
+		return &demostats.Match{Warnings: []string{"round 1: Created new player for ID 3"}}, nil
+	})
+	assert.Equal(t, success+1, testutil.ToFloat64(parsesTotal.WithLabelValues("success")))
+	assert.Equal(t, warnings+1, testutil.ToFloat64(parserProblems.WithLabelValues("warning")))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+)
+
+// parse parses a demo read from r with opts and records the parse metrics.
+// The parse logs to the logger of ctx
+func (s *server) parse(ctx context.Context, r io.Reader, opts demostats.Options) (*demostats.Match, error) {
+	opts.Logger = loggerFrom(ctx)
+	cr := &countingReader{r: r}
+	return observeParse(func() int64 { return cr.n }, func() (*demostats.Match, error) {
+		return demostats.Parse(ctx, cr, opts)
+	})
+}
+
+// parseFile parses the demo file at path with opts and records the parse
+// metrics, see demostats.ParseFile
+func (s *server) parseFile(ctx context.Context, path string, opts demostats.Options) (*demostats.Match, error) {
+	size := func() int64 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0
+		}
+		return info.Size()
+	}
+	opts.Logger = loggerFrom(ctx)
+	return observeParse(size, func() (*demostats.Match, error) {
+		return demostats.ParseFile(ctx, path, opts)
+	})
+}
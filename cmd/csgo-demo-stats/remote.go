@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	log "github.com/sirupsen/logrus"
+)
+
+// errRemoteForbidden signals that a remote url is not allowed by the remote
+// policy
+var errRemoteForbidden = errors.New("remote url is not allowed")
+
+// errIncompleteDownload signals that a remote download ended before all bytes
+// announced by the remote were received
+var errIncompleteDownload = errors.New("remote download incomplete")
+
+// blockedNets are the loopback, private, link-local, multicast and otherwise
+// internal networks remote demos can't be downloaded from unless explicitly
+// allowed. NAT64, 6to4 and Teredo addresses are blocked as they can reach
+// any ipv4 address
+var blockedNets = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"2001::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// remotePolicy restricts the urls demos may be downloaded from
+type remotePolicy struct {
+	// Schemes allowed in urls
+	Schemes []string
+
+	// Hosts allowed in urls, "*.example.com" matches all subdomains. Empty
+	// allows all hosts
+	Hosts []string
+
+	// AllowedNets are internal networks that may be connected to anyway
+	AllowedNets []*net.IPNet
+
+	// MaxRedirects is the maximum number of redirects followed
+	MaxRedirects int
+}
+
+// defaultRemotePolicy allows downloads from all public http and https hosts
+func defaultRemotePolicy() remotePolicy {
+	return remotePolicy{
+		Schemes:      []string{"http", "https"},
+		MaxRedirects: 5,
+	}
+}
+
+// checkURL returns an error if the scheme or host of the url is not allowed
+func (rp remotePolicy) checkURL(u *url.URL) error {
+	if !containsString(rp.Schemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q", errRemoteForbidden, u.Scheme)
+	}
+	if len(rp.Hosts) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, h := range rp.Hosts {
+		h = strings.ToLower(h)
+		if host == h || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q", errRemoteForbidden, host)
+}
+
+// checkIP returns an error if the ip is in a blocked network that is not
+// explicitly allowed
+func (rp remotePolicy) checkIP(ip net.IP) error {
+	for _, n := range rp.AllowedNets {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return fmt.Errorf("%w: address %s", errRemoteForbidden, ip)
+		}
+	}
+	return nil
+}
+
+// remoteClient downloads demo files from remote urls that are allowed by the
+// remote policy
+type remoteClient struct {
+	policy  remotePolicy
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// newRemoteClient constructor for a remote client. The policy is enforced on
+// the url, every redirect and on the addresses connected to after the dns
+// resolution
+func newRemoteClient(policy remotePolicy) *remoteClient {
+	return &remoteClient{
+		policy:  policy,
+		client:  policyClient(policy, time.Minute*20),
+		retries: 5,
+		backoff: time.Second,
+	}
+}
+
+// policyClient returns an http client only connecting to addresses allowed
+// by the policy. Redirects are limited and checked against the policy
+func policyClient(policy remotePolicy, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   time.Second * 30,
+		KeepAlive: time.Second * 30,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return policy.checkIP(net.ParseIP(host))
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy, the dialer has to see the address of the remote
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: time.Second * 10,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > policy.MaxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", errRemoteForbidden, policy.MaxRedirects)
+			}
+			return policy.checkURL(req.URL)
+		},
+	}
+}
+
+// checkHost returns an error if the host of the url is or resolves to a
+// blocked address. Hosts that can't be resolved are left to the check when
+// connecting
+func (rp remotePolicy) checkHost(u *url.URL) error {
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return rp.checkIP(ip)
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if err := rp.checkIP(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteStatusError is returned when the remote responds with an unexpected
+// status code
+type remoteStatusError struct {
+	status string
+	code   int
+}
+
+func (e *remoteStatusError) Error() string {
+	return "remote url returned: " + e.status
+}
+
+// remoteDownload is the state of a download that is resumed across requests
+type remoteDownload struct {
+	url     string
+	auth    string
+	maxSize int64
+	file    *os.File
+
+	// written is the number of bytes in file, total the size announced by
+	// the remote or -1 if unknown
+	written int64
+	total   int64
+
+	// validator is the ETag or Last-Modified date sent as If-Range, so a
+	// changed file isn't resumed
+	validator string
+}
+
+// download saves a demo file from a remote url to a temporary file and
+// returns its path, which has to be removed with removeDownload. auth is sent
+// as the full Authorization header if set.
+//
+// Failed requests and broken connections are retried with exponential
+// backoff, resuming with range requests from the bytes already received.
+// Downloads of more than maxSize bytes are rejected
+func (rc *remoteClient) download(ctx context.Context, rawURL string, auth string, maxSize int64) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := rc.policy.checkURL(u); err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "demo-stats-")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, remoteFileName(u))
+	f, err := os.Create(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	defer f.Close()
+
+	dl := &remoteDownload{url: u.String(), auth: auth, maxSize: maxSize, file: f, total: -1}
+	backoff := rc.backoff
+	for attempt := 0; ; attempt++ {
+		err = rc.fetch(ctx, dl)
+		if err == nil {
+			return path, nil
+		}
+		if !retryable(err) || attempt >= rc.retries {
+			os.RemoveAll(dir)
+			return "", err
+		}
+
+		loggerFrom(ctx).Warning("remote download failed after ", dl.written, " bytes, retrying in ", backoff, ": ", err)
+		select {
+		case <-ctx.Done():
+			os.RemoveAll(dir)
+			return "", ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// fetch requests the part of the download that is still missing and appends
+// it to the file
+func (rc *remoteClient) fetch(ctx context.Context, dl *remoteDownload) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", dl.url, nil)
+	if err != nil {
+		return err
+	}
+	if dl.auth != "" {
+		req.Header.Set("Authorization", dl.auth)
+	}
+	// Compressed responses can't be resumed at a byte offset of the demo
+	req.Header.Set("Accept-Encoding", "identity")
+	if dl.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", dl.written))
+		if dl.validator != "" {
+			req.Header.Set("If-Range", dl.validator)
+		}
+	}
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// End of the body for a demo of unknown size, -1 if the Content-Range
+	// doesn't tell
+	end := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		start, last, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != dl.written {
+			// Start over with the next attempt
+			if err := dl.reset(); err != nil {
+				return err
+			}
+			return fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		if dl.written == 0 {
+			// Some remotes answer every request with a range, even the
+			// first one without a Range header
+			dl.validator = responseValidator(resp)
+		}
+		dl.total = total
+		end = last + 1
+	case resp.StatusCode == http.StatusOK:
+		// The first request, or the remote doesn't support ranges or the file
+		// changed
+		if err := dl.reset(); err != nil {
+			return err
+		}
+		dl.total = resp.ContentLength
+		dl.validator = responseValidator(resp)
+	default:
+		return &remoteStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+
+	if dl.maxSize > 0 && dl.total > dl.maxSize {
+		return demostats.ErrDemoTooLarge
+	}
+
+	var body io.Reader = resp.Body
+	if dl.maxSize > 0 {
+		body = io.LimitReader(resp.Body, dl.maxSize-dl.written+1)
+	}
+	n, err := io.Copy(dl.file, body)
+	dl.written += n
+	if dl.maxSize > 0 && dl.written > dl.maxSize {
+		return demostats.ErrDemoTooLarge
+	}
+	if err != nil {
+		return err
+	}
+	if dl.total >= 0 && dl.written != dl.total {
+		return fmt.Errorf("%w: received %d of %d bytes", errIncompleteDownload, dl.written, dl.total)
+	}
+	if dl.total < 0 && !bodyEnded(resp, end, dl.written) {
+		return fmt.Errorf("%w: received %d bytes of unknown length", errIncompleteDownload, dl.written)
+	}
+	return nil
+}
+
+// bodyEnded reports whether a body of unknown length was received in full.
+// That's the case if the last byte of the Content-Range arrived, or if the
+// body is chunked or sent over HTTP/2, where the client reports an unexpected
+// EOF unless the body was properly terminated. A body ended by closing the
+// connection can't be told apart from a broken download
+func bodyEnded(resp *http.Response, end int64, written int64) bool {
+	if end >= 0 {
+		return written == end
+	}
+	if resp.ProtoMajor >= 2 {
+		return true
+	}
+	for _, te := range resp.TransferEncoding {
+		if te == "chunked" {
+			return true
+		}
+	}
+	return false
+}
+
+// responseValidator returns the ETag of the response for If-Range, or its
+// Last-Modified date if the ETag is missing or weak
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// reset drops the bytes received so far
+func (dl *remoteDownload) reset() error {
+	dl.written = 0
+	dl.total = -1
+	if err := dl.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := dl.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// retryable reports whether a failed download request is worth retrying
+func retryable(err error) bool {
+	var statusErr *remoteStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500 || statusErr.code == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, errRemoteForbidden) &&
+		!errors.Is(err, demostats.ErrDemoTooLarge) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// parseContentRange parses the first and the last byte and the total size
+// of a "bytes first-last/total" Content-Range header. The total is -1 if
+// unknown
+func parseContentRange(h string) (int64, int64, int64, bool) {
+	if !strings.HasPrefix(h, "bytes ") {
+		return 0, 0, 0, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(h, "bytes "), "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, 0, false
+	}
+	bounds := strings.SplitN(parts[0], "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, 0, false
+	}
+	first, err := strconv.ParseInt(bounds[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	last, err := strconv.ParseInt(bounds[1], 10, 64)
+	if err != nil || last < first {
+		return 0, 0, 0, false
+	}
+	if parts[1] == "*" {
+		return first, last, -1, true
+	}
+	total, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return first, last, total, true
+}
+
+// remoteFileName returns the file name of a remote demo, so the match id can
+// be taken from it like for demos parsed from disk
+func remoteFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || name == ".." {
+		return "remote.dem"
+	}
+	return name
+}
+
+// removeDownload removes a file returned by download
+func removeDownload(path string) {
+	if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+		log.Error("failed to remove download: ", err)
+	}
+}
+
+// remotePolicyFromEnv reads the remote policy from the environment, unset
+// variables keep the defaults
+func remotePolicyFromEnv() (remotePolicy, error) {
+	policy := defaultRemotePolicy()
+
+	if v, ok := os.LookupEnv("DEMO_STATS_REMOTE_SCHEMES"); ok {
+		policy.Schemes = splitList(v)
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_REMOTE_HOSTS"); ok {
+		policy.Hosts = splitList(v)
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_REMOTE_ALLOWED_NETS"); ok {
+		for _, cidr := range splitList(v) {
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return policy, fmt.Errorf("invalid DEMO_STATS_REMOTE_ALLOWED_NETS: %v", err)
+			}
+			policy.AllowedNets = append(policy.AllowedNets, n)
+		}
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_REMOTE_MAX_REDIRECTS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return policy, fmt.Errorf("invalid DEMO_STATS_REMOTE_MAX_REDIRECTS: %v", err)
+		}
+		policy.MaxRedirects = n
+	}
+
+	return policy, nil
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	out := []*net.IPNet{}
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(s string) []string {
+	out := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
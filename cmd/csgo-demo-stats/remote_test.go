@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRemoteClient returns a remote client allowed to connect to loopback
+// addresses, retrying without delay
+func testRemoteClient() *remoteClient {
+	rc := newRemoteClient(loopbackPolicy())
+	rc.backoff = time.Millisecond
+	return rc
+}
+
+func loopbackPolicy() remotePolicy {
+	policy := defaultRemotePolicy()
+	policy.AllowedNets = parseCIDRs("127.0.0.0/8", "::1/128")
+	return policy
+}
+
+func TestRemoteBlocksLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("demo"))
+	}))
+	defer srv.Close()
+
+	_, err := newRemoteClient(defaultRemotePolicy()).download(context.Background(), srv.URL, "", 0)
+	assert.True(t, errors.Is(err, errRemoteForbidden), "got %v", err)
+
+	path, err := newRemoteClient(loopbackPolicy()).download(context.Background(), srv.URL, "", 0)
+	assert.NoError(t, err)
+	defer removeDownload(path)
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "demo", string(b))
+}
+
+func TestRemotePolicyIP(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"2001:4860:4860::8888":   true,
+		"127.0.0.1":              false,
+		"::ffff:127.0.0.1":       false,
+		"100.64.0.1":             false,
+		"169.254.169.254":        false,
+		"192.0.0.170":            false,
+		"198.18.0.1":             false,
+		"198.19.255.254":         false,
+		"224.0.0.251":            false,
+		"239.255.255.250":        false,
+		"240.0.0.1":              false,
+		"255.255.255.255":        false,
+		"64:ff9b::a9fe:a9fe":     false,
+		"64:ff9b::5db8:d822":     false,
+		"2001:0:4136:e378::a9fe": false,
+		"2002:a9fe:a9fe::1":      false,
+		"ff02::1":                false,
+		"fd00:ec2::254":          false,
+		"fe80::1":                false,
+	}
+
+	policy := defaultRemotePolicy()
+	for ip, allowed := range cases {
+		err := policy.checkIP(net.ParseIP(ip))
+		assert.Equal(t, !allowed, errors.Is(err, errRemoteForbidden), "%s: got %v", ip, err)
+	}
+}
+
+func TestRemotePolicyURL(t *testing.T) {
+	policy := loopbackPolicy()
+	policy.Hosts = []string{"demos.example.com", "*.fastdl.example.com"}
+
+	cases := map[string]bool{
+		"https://demos.example.com/a.dem":       true,
+		"https://eu.fastdl.example.com/a.dem":   true,
+		"https://fastdl.example.com.evil/a.dem": false,
+		"https://example.com/a.dem":             false,
+		"ftp://demos.example.com/a.dem":         false,
+		"file:///etc/passwd":                    false,
+	}
+
+	rc := newRemoteClient(policy)
+	rc.retries = 0
+	for u, allowed := range cases {
+		_, err := rc.download(context.Background(), u, "", 0)
+		assert.Equal(t, !allowed, errors.Is(err, errRemoteForbidden), "%s: got %v", u, err)
+	}
+}
+
+func TestRemoteRedirects(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, srv.URL+"/loop", http.StatusFound)
+		case "/internal":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	policy := loopbackPolicy()
+	policy.MaxRedirects = 2
+	rc := newRemoteClient(policy)
+
+	_, err := rc.download(context.Background(), srv.URL+"/loop", "", 0)
+	assert.True(t, errors.Is(err, errRemoteForbidden), "got %v", err)
+
+	_, err = rc.download(context.Background(), srv.URL+"/internal", "", 0)
+	assert.True(t, errors.Is(err, errRemoteForbidden), "got %v", err)
+}
+
+func TestRemoteConnectionError(t *testing.T) {
+	// Grab a free port and close it again so nothing is listening
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	_, err = testRemoteClient().download(context.Background(), "http://"+addr, "", 0)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, errRemoteForbidden))
+}
+
+func TestRemoteResume(t *testing.T) {
+	demo := bytes.Repeat([]byte("0123456789"), 1000)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			// Break the connection halfway through the first download
+			w.Header().Set("Content-Length", strconv.Itoa(len(demo)))
+			w.Header().Set("ETag", `"demo"`)
+			w.Write(demo[:len(demo)/2])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		assert.Equal(t, "bytes="+strconv.Itoa(len(demo)/2)+"-", r.Header.Get("Range"))
+		assert.Equal(t, `"demo"`, r.Header.Get("If-Range"))
+		w.Header().Set("ETag", `"demo"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(demo))
+	}))
+	defer srv.Close()
+
+	path, err := testRemoteClient().download(context.Background(), srv.URL+"/1234_de_dust2.dem", "", 0)
+	assert.NoError(t, err)
+	defer removeDownload(path)
+
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, demo, b)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "1234_de_dust2.dem", filepath.Base(path))
+}
+
+func TestRemoteRetries(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/flaky":
+			if requests < 3 {
+				w.WriteHeader(503)
+				return
+			}
+			w.Write([]byte("demo"))
+		case "/missing":
+			w.WriteHeader(404)
+		case "/large":
+			w.Write(make([]byte, 100))
+		}
+	}))
+	defer srv.Close()
+	rc := testRemoteClient()
+
+	path, err := rc.download(context.Background(), srv.URL+"/flaky", "", 0)
+	assert.NoError(t, err)
+	removeDownload(path)
+	assert.Equal(t, 3, requests)
+
+	// Client errors and too large demos are not retried
+	requests = 0
+	_, err = rc.download(context.Background(), srv.URL+"/missing", "", 0)
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+
+	requests = 0
+	_, err = rc.download(context.Background(), srv.URL+"/large", "", 50)
+	assert.True(t, errors.Is(err, demostats.ErrDemoTooLarge), "got %v", err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestParseContentRange(t *testing.T) {
+	first, last, total, ok := parseContentRange("bytes 100-199/200")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), first)
+	assert.Equal(t, int64(199), last)
+	assert.Equal(t, int64(200), total)
+
+	first, last, total, ok = parseContentRange("bytes 100-199/*")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), first)
+	assert.Equal(t, int64(199), last)
+	assert.Equal(t, int64(-1), total)
+
+	_, _, _, ok = parseContentRange("items 1-2/3")
+	assert.False(t, ok)
+	_, _, _, ok = parseContentRange("bytes 100/200")
+	assert.False(t, ok)
+}
+
+func TestRemoteUnknownLength(t *testing.T) {
+	demo := bytes.Repeat([]byte("0123456789"), 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chunked":
+			// Flushing before the end sends the body chunked
+			w.Write(demo[:10])
+			w.(http.Flusher).Flush()
+			w.Write(demo[10:])
+		case "/closed":
+			// Without a length the end of the body is the end of the
+			// connection, a download cut short looks the same
+			conn, buf, _ := w.(http.Hijacker).Hijack()
+			buf.WriteString("HTTP/1.1 200 OK\r\nConnection: close\r\n\r\n")
+			buf.Write(demo)
+			buf.Flush()
+			conn.Close()
+		case "/range":
+			// A full response to a request without Range
+			assert.Empty(t, r.Header.Get("Range"))
+			w.Header().Set("Content-Range", "bytes 0-"+strconv.Itoa(len(demo)-1)+"/*")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(demo)
+		}
+	}))
+	defer srv.Close()
+	rc := testRemoteClient()
+
+	for _, p := range []string{"/chunked", "/range"} {
+		path, err := rc.download(context.Background(), srv.URL+p, "", 0)
+		assert.NoError(t, err, p)
+		b, _ := ioutil.ReadFile(path)
+		assert.Equal(t, demo, b, p)
+		removeDownload(path)
+	}
+
+	_, err := rc.download(context.Background(), srv.URL+"/closed", "", 0)
+	assert.True(t, errors.Is(err, errIncompleteDownload), "got %v", err)
+}
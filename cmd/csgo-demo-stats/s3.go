@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	log "github.com/sirupsen/logrus"
+)
+
+// errS3NotConfigured signals that no object storage endpoint is configured
+var errS3NotConfigured = errors.New("object storage is not configured, set DEMO_STATS_S3_ENDPOINT")
+
+// emptyPayloadHash is the hex encoded SHA256 hash of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3Error is returned when the object storage responds with an error status
+type s3Error struct {
+	Status  int    `xml:"-"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("object storage returned status %d", e.Status)
+	}
+	return fmt.Sprintf("object storage returned %s: %s", e.Code, e.Message)
+}
+
+// s3Object is an object of a bucket listing
+type s3Object struct {
+	Key  string `xml:"Key"`
+	ETag string `xml:"ETag"`
+	Size int64  `xml:"Size"`
+}
+
+// s3Client fetches demos from an S3 compatible object storage like MinIO.
+// Buckets are addressed path style and requests are signed with AWS
+// Signature Version 4
+type s3Client struct {
+	endpoint     *url.URL
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+// s3ClientFromEnv reads the object storage configuration from the
+// environment. Returns nil if DEMO_STATS_S3_ENDPOINT is not set
+func s3ClientFromEnv() (*s3Client, error) {
+	endpoint, ok := os.LookupEnv("DEMO_STATS_S3_ENDPOINT")
+	if !ok || endpoint == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid DEMO_STATS_S3_ENDPOINT %q", endpoint)
+	}
+
+	region, ok := os.LookupEnv("DEMO_STATS_S3_REGION")
+	if !ok {
+		region = "us-east-1"
+	}
+	return &s3Client{
+		endpoint:     u,
+		region:       region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       &http.Client{Timeout: time.Minute * 20},
+	}, nil
+}
+
+// splitObject splits a "bucket/key" object reference
+func splitObject(object string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(object, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid object %q, expected bucket/key", object)
+	}
+	return parts[0], parts[1], nil
+}
+
+// download saves an object to a temporary file and returns its path, which
+// has to be removed with removeDownload. Downloads of more than maxSize bytes
+// are rejected
+func (sc *s3Client) download(ctx context.Context, bucket string, key string, maxSize int64) (string, error) {
+	resp, err := sc.do(ctx, bucket, key, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return "", demostats.ErrDemoTooLarge
+	}
+
+	dir, err := ioutil.TempDir("", "demo-stats-")
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, remoteFileName(&url.URL{Path: key}))
+	f, err := os.Create(file)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	defer f.Close()
+
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	n, err := io.Copy(f, body)
+	if err == nil && maxSize > 0 && n > maxSize {
+		err = demostats.ErrDemoTooLarge
+	}
+	if err == nil && resp.ContentLength >= 0 && n != resp.ContentLength {
+		err = fmt.Errorf("%w: received %d of %d bytes", errIncompleteDownload, n, resp.ContentLength)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return file, nil
+}
+
+// list returns all objects of the bucket with keys starting with prefix
+func (sc *s3Client) list(ctx context.Context, bucket string, prefix string) ([]s3Object, error) {
+	objects := []s3Object{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := sc.do(ctx, bucket, "", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents              []s3Object `xml:"Contents"`
+			IsTruncated           bool       `xml:"IsTruncated"`
+			NextContinuationToken string     `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a signed GET request for the bucket and key, error responses are
+// returned as s3Error
+func (sc *s3Client) do(ctx context.Context, bucket string, key string, query url.Values) (*http.Response, error) {
+	p := "/" + bucket
+	if key != "" {
+		p += "/" + key
+	}
+	u := *sc.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + p
+	u.RawPath = s3Escape(u.Path, true)
+	u.RawQuery = s3Query(query)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	sc.sign(req, time.Now())
+
+	resp, err := sc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		s3Err := &s3Error{}
+		_ = xml.NewDecoder(resp.Body).Decode(s3Err)
+		s3Err.Status = resp.StatusCode
+		return nil, s3Err
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 Authorization header to a request
+// without body. All headers set on the request are signed
+func (sc *s3Client) sign(req *http.Request, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if sc.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sc.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := date + "/" + sc.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+sc.secretKey), date)
+	key = hmacSHA256(key, sc.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+sc.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape percent encodes everything but unreserved characters, as
+// required by Signature Version 4. Slashes are kept if keepSlash is set
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && keepSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3Query encodes query parameters sorted by name, as required by Signature
+// Version 4
+func s3Query(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{}
+	for _, name := range names {
+		for _, v := range query[name] {
+			parts = append(parts, s3Escape(name, false)+"="+s3Escape(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Ingester parses every new demo below a prefix of a bucket. The objects
+// already ingested are remembered in a state file, so restarts don't parse
+// them again
+type s3Ingester struct {
+	s3        *s3Client
+	bucket    string
+	prefix    string
+	statePath string
+	maxSize   int64
+	parse     func(ctx context.Context, path string) error
+
+	mu   sync.Mutex
+	seen map[string]string
+}
+
+// newS3Ingester constructor for an ingester of the "bucket/prefix" location.
+// parse is called with the path of every downloaded demo. The state file is
+// loaded if it exists, an empty state path keeps the state in memory only
+func newS3Ingester(sc *s3Client, location string, statePath string, maxSize int64,
+	parse func(ctx context.Context, path string) error) (*s3Ingester, error) {
+	parts := strings.SplitN(strings.TrimPrefix(location, "/"), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid location %q, expected bucket/prefix", location)
+	}
+	in := &s3Ingester{
+		s3:        sc,
+		bucket:    parts[0],
+		statePath: statePath,
+		maxSize:   maxSize,
+		parse:     parse,
+		seen:      make(map[string]string),
+	}
+	if len(parts) == 2 {
+		in.prefix = parts[1]
+	}
+
+	if statePath != "" {
+		b, err := ioutil.ReadFile(statePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &in.seen); err != nil {
+				return nil, fmt.Errorf("invalid ingest state %s: %v", statePath, err)
+			}
+		}
+	}
+	return in, nil
+}
+
+// run ingests new demos every interval until ctx is done
+func (in *s3Ingester) run(ctx context.Context, interval time.Duration) {
+	for {
+		if _, err := in.ingest(ctx); err != nil {
+			log.Error("object storage ingest failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ingest parses all .dem objects that weren't ingested before, or changed
+// since, and returns how many were ingested. Objects that can't be
+// downloaded are retried with the next ingest, demos that fail to parse are
+// not
+func (in *s3Ingester) ingest(ctx context.Context) (int, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	objects, err := in.s3.list(ctx, in.bucket, in.prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	ingested := 0
+	for _, obj := range objects {
+		if !strings.HasSuffix(strings.ToLower(obj.Key), ".dem") {
+			continue
+		}
+		if etag, ok := in.seen[obj.Key]; ok && etag == obj.ETag {
+			continue
+		}
+
+		start := time.Now()
+		file, err := in.s3.download(ctx, in.bucket, obj.Key, in.maxSize)
+		observeDownload("object", start, err)
+		switch {
+		case errors.Is(err, context.Canceled):
+			return ingested, err
+		case errors.Is(err, demostats.ErrDemoTooLarge):
+			log.Error("skipping ", in.bucket, "/", obj.Key, ": ", err)
+		case err != nil:
+			log.Warning("failed to download ", in.bucket, "/", obj.Key, ", retrying with the next ingest: ", err)
+			continue
+		default:
+			logger := log.WithField("object", in.bucket+"/"+obj.Key)
+			err = in.parse(withLogger(ctx, logger), file)
+			removeDownload(file)
+			if errors.Is(err, context.Canceled) {
+				return ingested, err
+			}
+			if err != nil {
+				logger.Error("failed to parse: ", err)
+			} else {
+				logger.Info("ingested")
+			}
+		}
+
+		ingested++
+		in.seen[obj.Key] = obj.ETag
+		if err := in.saveState(); err != nil {
+			return ingested, err
+		}
+	}
+	return ingested, nil
+}
+
+// saveState writes the ingested objects to the state file
+func (in *s3Ingester) saveState() error {
+	if in.statePath == "" {
+		return nil
+	}
+	return writeJSONFile(in.statePath, in.seen)
+}
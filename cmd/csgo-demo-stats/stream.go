@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+)
+
+// parseFunc runs a parse with the options of the request
+type parseFunc func(ctx context.Context, opts demostats.Options) (*demostats.Match, error)
+
+// respond runs the parse with opts and writes its result, see writeResult.
+// Streamed responses send server-sent events instead, see streamResult
+func (s *server) respond(c *gin.Context, stream bool, callbackURL string, opts demostats.Options, parse parseFunc) {
+	if stream {
+		s.streamResult(c, callbackURL, opts, parse)
+		return
+	}
+	matchInfo, err := parse(c.Request.Context(), opts)
+	if err != nil {
+		writeParseError(c, err)
+		return
+	}
+	s.notify(matchInfo, callbackURL)
+	writeResult(c, matchInfo)
+}
+
+// streamResult runs the parse sending a "progress" event whenever the parsed
+// percent, the round or the score changes. The scoreboard is sent as the
+// "result" event at the end, a failed parse ends with an "error" event
+func (s *server) streamResult(c *gin.Context, callbackURL string, opts demostats.Options, parse parseFunc) {
+	opts.Progress = func(progress demostats.Progress) {
+		c.SSEvent("progress", progress)
+		c.Writer.Flush()
+	}
+	matchInfo, err := parse(c.Request.Context(), opts)
+	if err != nil {
+		if !c.Writer.Written() || errors.Is(err, context.Canceled) {
+			writeParseError(c, err)
+			return
+		}
+		_, kind := parseErrorStatus(err)
+		c.SSEvent("error", parseErrorResponse{Error: kind, Message: err.Error()})
+		return
+	}
+
+	s.notify(matchInfo, callbackURL)
+	c.SSEvent("result", matchInfo.GetScoreboard())
+}
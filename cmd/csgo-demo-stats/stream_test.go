@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamResult(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := &server{webhooks: NewWebhookNotifier("", defaultRemotePolicy())}
+	var parseErr error
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		srv.respond(c, true, "", demostats.Options{}, func(ctx context.Context, opts demostats.Options) (*demostats.Match, error) {
+			opts.Progress(demostats.Progress{Percent: 10, Round: 1})
+			opts.Progress(demostats.Progress{Percent: 20, Round: 2, ScoreA: 1})
+			return &demostats.Match{MatchValid: true}, parseErr
+		})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "event:progress\ndata:{\"percent\":10,\"round\":1,\"score_a\":0,\"score_b\":0}\n\n"+
+		"event:progress\ndata:{\"percent\":20,\"round\":2,\"score_a\":1,\"score_b\":0}\n\n"+
+		"event:result\n")
+
+	parseErr = demostats.ErrTruncatedDemo
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Contains(t, w.Body.String(), "event:error\ndata:{\"error\":\"truncated_demo\"")
+}
+
+func TestStreamResultFailedEarly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := &server{webhooks: NewWebhookNotifier("", defaultRemotePolicy())}
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		srv.respond(c, true, "", demostats.Options{}, func(ctx context.Context, opts demostats.Options) (*demostats.Match, error) {
+			return nil, demostats.ErrInvalidFile
+		})
+	})
+
+	// Nothing was streamed yet, the error is a plain response
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_file")
+}
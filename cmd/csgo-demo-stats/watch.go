@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	log "github.com/sirupsen/logrus"
+)
+
+// watchedFile is a demo file that is waited on to stop growing
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// processedFile is a demo file that was processed but may not be archived
+// yet
+type processedFile struct {
+	Fingerprint string `json:"fingerprint"`
+	ArchiveDir  string `json:"archive_dir"`
+}
+
+// dirWatcher parses the demo files written to a directory, e.g. by game
+// servers when a match ends. Files are parsed once they stopped growing and
+// are moved to the archive directory afterwards, demos that are no valid csgo
+// demos to its "failed" subdirectory. Other failed parses are retried.
+//
+// Processed files are remembered in a state file until they are archived, so
+// a restart in between doesn't parse them again
+type dirWatcher struct {
+	dir        string
+	archiveDir string
+	resultsDir string
+	statePath  string
+	settle     time.Duration
+
+	parse  func(ctx context.Context, path string) (*demostats.Match, error)
+	notify func(matchInfo *demostats.Match)
+
+	pending   map[string]watchedFile
+	processed map[string]processedFile
+}
+
+// newDirWatcher constructor for a watcher of dir. Results are written as
+// JSON to resultsDir if set. The state file is loaded if it exists
+func newDirWatcher(dir string, archiveDir string, resultsDir string, statePath string, settle time.Duration) (*dirWatcher, error) {
+	for _, d := range []string{archiveDir, filepath.Join(archiveDir, "failed"), resultsDir} {
+		if d == "" {
+			continue
+		}
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	dw := &dirWatcher{
+		dir:        dir,
+		archiveDir: archiveDir,
+		resultsDir: resultsDir,
+		statePath:  statePath,
+		settle:     settle,
+		notify:     func(*demostats.Match) {},
+		pending:    make(map[string]watchedFile),
+		processed:  make(map[string]processedFile),
+	}
+	b, err := ioutil.ReadFile(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &dw.processed); err != nil {
+			return nil, fmt.Errorf("invalid watch state %s: %v", statePath, err)
+		}
+	}
+	return dw, nil
+}
+
+// run scans the directory every interval until ctx is done
+func (dw *dirWatcher) run(ctx context.Context, interval time.Duration) {
+	for {
+		if _, err := dw.scan(ctx); err != nil {
+			log.Error("watching ", dw.dir, " failed: ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// scan processes the demo files that didn't change for the settle duration
+// and returns how many were parsed
+func (dw *dirWatcher) scan(ctx context.Context) (int, error) {
+	infos, err := ioutil.ReadDir(dw.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	present := make(map[string]bool)
+	parsed := 0
+	for _, info := range infos {
+		name := info.Name()
+		if !info.Mode().IsRegular() || !strings.HasSuffix(strings.ToLower(name), ".dem") {
+			continue
+		}
+		present[name] = true
+
+		fingerprint := strconv.FormatInt(info.Size(), 10) + "@" + info.ModTime().UTC().Format(time.RFC3339Nano)
+		if p, ok := dw.processed[name]; ok && p.Fingerprint == fingerprint {
+			// Processed before a restart, but not archived yet
+			dw.archive(name, p.ArchiveDir)
+			continue
+		}
+
+		file, ok := dw.pending[name]
+		if !ok || file.size != info.Size() || !file.modTime.Equal(info.ModTime()) {
+			dw.pending[name] = watchedFile{size: info.Size(), modTime: info.ModTime(), since: now}
+			continue
+		}
+		if now.Sub(file.since) < dw.settle {
+			continue
+		}
+
+		dest, err := dw.process(ctx, name)
+		if err != nil {
+			return parsed, err
+		}
+		if dest == "" {
+			// Parsed again after the settle duration
+			dw.pending[name] = watchedFile{size: info.Size(), modTime: info.ModTime(), since: now}
+			continue
+		}
+		parsed++
+		delete(dw.pending, name)
+		dw.processed[name] = processedFile{Fingerprint: fingerprint, ArchiveDir: dest}
+		if err := writeJSONFile(dw.statePath, dw.processed); err != nil {
+			return parsed, err
+		}
+		dw.archive(name, dest)
+	}
+
+	for name := range dw.pending {
+		if !present[name] {
+			delete(dw.pending, name)
+		}
+	}
+	return parsed, nil
+}
+
+// process parses a demo file and stores or sends the result. Returns the
+// directory the file is archived to, empty if the parse failed for a reason
+// that may go away and the file stays. Only errors that should stop the
+// watcher are returned
+func (dw *dirWatcher) process(ctx context.Context, name string) (string, error) {
+	logger := log.WithField("file", name)
+	matchInfo, err := dw.parse(withLogger(ctx, logger), filepath.Join(dw.dir, name))
+	if errors.Is(err, context.Canceled) {
+		return "", err
+	}
+	if errors.Is(err, demostats.ErrInvalidFile) || errors.Is(err, demostats.ErrUnsupportedDemoVersion) {
+		logger.Error("failed to parse: ", err)
+		return filepath.Join(dw.archiveDir, "failed"), nil
+	}
+	if err != nil {
+		logger.Warning("failed to parse, retrying: ", err)
+		return "", nil
+	}
+
+	if dw.resultsDir != "" {
+		result := filepath.Join(dw.resultsDir, strings.TrimSuffix(name, filepath.Ext(name))+".json")
+		if err := writeJSONFile(result, matchInfo); err != nil {
+			return "", err
+		}
+	}
+	dw.notify(matchInfo)
+	logger.Info("parsed")
+	return dw.archiveDir, nil
+}
+
+// archive moves a processed demo file to the directory and forgets it
+func (dw *dirWatcher) archive(name string, dir string) {
+	if err := moveFile(filepath.Join(dw.dir, name), filepath.Join(dir, name)); err != nil {
+		log.Error("failed to archive ", name, ": ", err)
+		return
+	}
+	delete(dw.processed, name)
+	if err := writeJSONFile(dw.statePath, dw.processed); err != nil {
+		log.Error("failed to save watch state: ", err)
+	}
+}
+
+// moveFile renames a file, copying it if the destination is on another
+// device
+func moveFile(src string, dest string) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// writeJSONFile writes v as JSON to a temporary file and renames it, so a
+// crash never leaves a half written file behind
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// watcherFromEnv creates the watcher of the directory. The archive defaults
+// to the "archive" subdirectory, files have to be unchanged for 30 seconds
+// and the directory is scanned every 5 seconds by default
+func (s *server) watcherFromEnv(dir string) (*dirWatcher, time.Duration, error) {
+	archiveDir, ok := os.LookupEnv("DEMO_STATS_WATCH_ARCHIVE")
+	if !ok {
+		archiveDir = filepath.Join(dir, "archive")
+	}
+	resultsDir, _ := os.LookupEnv("DEMO_STATS_WATCH_RESULTS")
+
+	settle := time.Second * 30
+	interval := time.Second * 5
+	if v, ok := os.LookupEnv("DEMO_STATS_WATCH_SETTLE"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid DEMO_STATS_WATCH_SETTLE: %v", err)
+		}
+		settle = d
+	}
+	if v, ok := os.LookupEnv("DEMO_STATS_WATCH_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid DEMO_STATS_WATCH_INTERVAL: %v", err)
+		}
+		interval = d
+	}
+
+	dw, err := newDirWatcher(dir, archiveDir, resultsDir, filepath.Join(archiveDir, ".processed.json"), settle)
+	if err != nil {
+		return nil, 0, err
+	}
+	dw.parse = s.parseFileQueued
+	dw.notify = func(matchInfo *demostats.Match) {
+		s.notify(matchInfo, "")
+	}
+	return dw, interval, nil
+}
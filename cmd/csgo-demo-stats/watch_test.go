@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDirWatcher(t *testing.T, dir string, parsed *[]string) *dirWatcher {
+	dw, err := newDirWatcher(dir, filepath.Join(dir, "archive"), filepath.Join(dir, "results"),
+		filepath.Join(dir, "archive", ".processed.json"), 0)
+	assert.NoError(t, err)
+	dw.parse = func(ctx context.Context, path string) (*demostats.Match, error) {
+		*parsed = append(*parsed, filepath.Base(path))
+		b, _ := ioutil.ReadFile(path)
+		if string(b) == "broken" {
+			return nil, demostats.ErrInvalidFile
+		}
+		return &demostats.Match{MatchID: "1234"}, nil
+	}
+	return dw
+}
+
+func TestDirWatcher(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	parsed := []string{}
+	dw := testDirWatcher(t, dir, &parsed)
+
+	demo := filepath.Join(dir, "1234_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(demo, []byte("demo"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "5678_de_nuke.dem"), []byte("broken"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("text"), 0644))
+
+	// Files are only parsed once they didn't change between two scans
+	n, err := dw.scan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	f, _ := os.OpenFile(demo, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write([]byte(" more"))
+	f.Close()
+	n, err = dw.scan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"5678_de_nuke.dem"}, parsed)
+	assert.FileExists(t, filepath.Join(dir, "archive", "failed", "5678_de_nuke.dem"))
+
+	n, err = dw.scan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"5678_de_nuke.dem", "1234_de_dust2.dem"}, parsed)
+	assert.FileExists(t, filepath.Join(dir, "archive", "1234_de_dust2.dem"))
+	assert.FileExists(t, filepath.Join(dir, "results", "1234_de_dust2.json"))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	_, err = os.Stat(demo)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDirWatcherRestart(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	parsed := []string{}
+	dw := testDirWatcher(t, dir, &parsed)
+
+	demo := filepath.Join(dir, "1234_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(demo, []byte("demo"), 0644))
+	info, _ := os.Stat(demo)
+
+	// Simulate a crash after the parse, before the file was archived
+	dw.processed["1234_de_dust2.dem"] = processedFile{
+		Fingerprint: "4@" + info.ModTime().UTC().Format("2006-01-02T15:04:05.999999999Z07:00"),
+		ArchiveDir:  filepath.Join(dir, "archive"),
+	}
+	assert.NoError(t, writeJSONFile(dw.statePath, dw.processed))
+
+	dw = testDirWatcher(t, dir, &parsed)
+	for i := 0; i < 2; i++ {
+		_, err := dw.scan(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, parsed)
+	assert.FileExists(t, filepath.Join(dir, "archive", "1234_de_dust2.dem"))
+	assert.Empty(t, dw.processed)
+}
+
+func TestDirWatcherCancelled(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	parsed := []string{}
+	dw := testDirWatcher(t, dir, &parsed)
+	dw.parse = func(ctx context.Context, path string) (*demostats.Match, error) {
+		return nil, context.Canceled
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "1234_de_dust2.dem"), []byte("demo"), 0644))
+
+	dw.scan(context.Background())
+	_, err := dw.scan(context.Background())
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.FileExists(t, filepath.Join(dir, "1234_de_dust2.dem"))
+}
+
+func TestDirWatcherRetry(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	parsed := []string{}
+	dw := testDirWatcher(t, dir, &parsed)
+	parseErr := demostats.ErrParseTimeout
+	dw.parse = func(ctx context.Context, path string) (*demostats.Match, error) {
+		parsed = append(parsed, filepath.Base(path))
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return &demostats.Match{MatchID: "1234"}, nil
+	}
+	demo := filepath.Join(dir, "1234_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(demo, []byte("demo"), 0644))
+
+	// Timeouts and other errors leave the demo to be parsed again
+	for _, err := range []error{demostats.ErrParseTimeout, context.DeadlineExceeded} {
+		parseErr = err
+		dw.scan(context.Background())
+		n, err := dw.scan(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.NotEmpty(t, parsed)
+		assert.FileExists(t, demo)
+		parsed = parsed[:0]
+	}
+
+	parseErr = nil
+	n, err := dw.scan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.FileExists(t, filepath.Join(dir, "archive", "1234_de_dust2.dem"))
+	assert.Empty(t, dw.processed)
+}
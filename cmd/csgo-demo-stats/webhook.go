@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	log "github.com/sirupsen/logrus"
+)
+
+// WebhookSignatureHeader is the header carrying the hex encoded HMAC-SHA256
+// signature of a webhook payload, prefixed with "sha256="
+const WebhookSignatureHeader = "X-Demo-Stats-Signature"
+
+// Webhook is a callback url registered to be notified when a parse finishes
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// WebhookNotifier holds the registered webhooks and delivers match summaries
+// to them. The webhooks are kept in memory only and have to be registered
+// again after a restart
+type WebhookNotifier struct {
+	mu      sync.RWMutex
+	hooks   map[string]Webhook
+	secret  []byte
+	policy  remotePolicy
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// NewWebhookNotifier constructor for a webhook notifier. Payloads are signed
+// with the given secret. Webhooks are only delivered to addresses allowed by
+// the policy, like remote demos are only downloaded from them
+func NewWebhookNotifier(secret string, policy remotePolicy) *WebhookNotifier {
+	return &WebhookNotifier{
+		hooks:   make(map[string]Webhook),
+		secret:  []byte(secret),
+		policy:  policy,
+		client:  policyClient(policy, time.Second*10),
+		retries: 5,
+		backoff: time.Second,
+	}
+}
+
+// Register adds a new webhook url that will receive all future match summaries
+func (wn *WebhookNotifier) Register(rawURL string) (Webhook, error) {
+	if err := wn.validateCallbackURL(rawURL); err != nil {
+		return Webhook{}, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Webhook{}, err
+	}
+
+	hook := Webhook{ID: hex.EncodeToString(id), URL: rawURL}
+
+	wn.mu.Lock()
+	wn.hooks[hook.ID] = hook
+	wn.mu.Unlock()
+
+	return hook, nil
+}
+
+// Remove deletes a registered webhook, returns false if the id is unknown
+func (wn *WebhookNotifier) Remove(id string) bool {
+	wn.mu.Lock()
+	defer wn.mu.Unlock()
+
+	if _, ok := wn.hooks[id]; !ok {
+		return false
+	}
+	delete(wn.hooks, id)
+	return true
+}
+
+// List returns all registered webhooks
+func (wn *WebhookNotifier) List() []Webhook {
+	wn.mu.RLock()
+	defer wn.mu.RUnlock()
+
+	out := []Webhook{}
+	for _, h := range wn.hooks {
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Notify sends the summary of a match to all registered webhooks and the
+// optional callback url of the request. Deliveries happen in the background.
+func (wn *WebhookNotifier) Notify(summary demostats.MatchSummary, callbackURL string) {
+	urls := []string{}
+	for _, h := range wn.List() {
+		urls = append(urls, h.URL)
+	}
+	if callbackURL != "" {
+		urls = append(urls, callbackURL)
+	}
+
+	for _, u := range urls {
+		go func(u string) {
+			if err := wn.Deliver(u, summary); err != nil {
+				log.Error("webhook delivery to ", u, " failed: ", err)
+			}
+		}(u)
+	}
+}
+
+// Deliver posts the summary to a single url, retrying with exponential
+// backoff on connection errors, 429 and 5xx responses
+func (wn *WebhookNotifier) Deliver(url string, summary demostats.MatchSummary) error {
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return wn.deliver(url, payload)
+}
+
+func (wn *WebhookNotifier) deliver(url string, payload []byte) error {
+	var err error
+	backoff := wn.backoff
+	for attempt := 0; ; attempt++ {
+		err = wn.post(url, payload)
+		if err == nil || attempt >= wn.retries || !retryable(err) {
+			return err
+		}
+		log.Warning("webhook delivery to ", url, " failed, retrying in ", backoff, ": ", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (wn *WebhookNotifier) post(url string, payload []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, "sha256="+wn.Sign(payload))
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &remoteStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload
+func (wn *WebhookNotifier) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, wn.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateCallbackURL returns an error if the url is no http or https url or
+// if the policy doesn't allow its host
+func (wn *WebhookNotifier) validateCallbackURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("invalid callback url: " + rawURL)
+	}
+	if err := wn.policy.checkURL(u); err != nil {
+		return err
+	}
+	return wn.policy.checkHost(u)
+}
+
+// NotifyDiscord posts the discord message of a match to a discord webhook url
+// in the background
+func (wn *WebhookNotifier) NotifyDiscord(url string, m *demostats.Match) {
+	payload, err := json.Marshal(m.DiscordMessage())
+	if err != nil {
+		log.Error("could not encode discord message: ", err)
+		return
+	}
+
+	go func() {
+		if err := wn.deliver(url, payload); err != nil {
+			log.Error("discord webhook delivery failed: ", err)
+		}
+	}()
+}
+
+// webhookPolicy returns the policy webhooks are delivered by. The hosts
+// remote demos may be downloaded from don't restrict webhooks, the blocked
+// networks, schemes and redirects do
+func webhookPolicy(policy remotePolicy) remotePolicy {
+	policy.Hosts = nil
+	return policy
+}
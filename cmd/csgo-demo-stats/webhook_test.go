@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/martig3/csgo-demo-stats/pkg/demostats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeliver(t *testing.T) {
+	wn := NewWebhookNotifier("secret", loopbackPolicy())
+	wn.backoff = time.Millisecond
+
+	calls := 0
+	var got demostats.MatchSummary
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		// Fail the first two attempts to exercise the retries
+		if calls < 3 {
+			w.WriteHeader(500)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "sha256="+wn.Sign(body), r.Header.Get(WebhookSignatureHeader))
+		assert.NoError(t, json.Unmarshal(body, &got))
+		w.WriteHeader(204)
+	}))
+	defer srv.Close()
+
+	summary := demostats.MatchSummary{MatchID: "1", MapName: "de_dust2", ScoreA: 16, ScoreB: 9}
+	assert.NoError(t, wn.Deliver(srv.URL, summary))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, summary, got)
+}
+
+func TestWebhookDeliverGivesUp(t *testing.T) {
+	wn := NewWebhookNotifier("secret", loopbackPolicy())
+	wn.backoff = time.Millisecond
+	wn.retries = 2
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(503)
+	}))
+	defer srv.Close()
+
+	assert.Error(t, wn.Deliver(srv.URL, demostats.MatchSummary{}))
+	assert.Equal(t, 3, calls)
+}
+
+func TestWebhookDeliverClientError(t *testing.T) {
+	wn := NewWebhookNotifier("secret", loopbackPolicy())
+	wn.backoff = time.Millisecond
+
+	for code, want := range map[int]int{400: 1, 404: 1, 429: 6, 502: 6} {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(code)
+		}))
+		assert.Error(t, wn.Deliver(srv.URL, demostats.MatchSummary{}))
+		assert.Equal(t, want, calls, code)
+		srv.Close()
+	}
+}
+
+func TestWebhookRegister(t *testing.T) {
+	wn := NewWebhookNotifier("", defaultRemotePolicy())
+
+	_, err := wn.Register("ftp://example.com")
+	assert.Error(t, err)
+
+	hook, err := wn.Register("https://example.com/hook")
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{hook}, wn.List())
+
+	assert.True(t, wn.Remove(hook.ID))
+	assert.False(t, wn.Remove(hook.ID))
+	assert.Empty(t, wn.List())
+}
+
+func TestWebhookBlocked(t *testing.T) {
+	wn := NewWebhookNotifier("", defaultRemotePolicy())
+	wn.retries = 0
+
+	// Internal hosts can't be registered
+	for _, u := range []string{"http://127.0.0.1/hook", "http://169.254.169.254/latest", "http://[::1]/hook", "http://10.0.0.1/hook", "http://localhost/hook"} {
+		_, err := wn.Register(u)
+		assert.True(t, errors.Is(err, errRemoteForbidden), "%s: got %v", u, err)
+	}
+
+	// Deliveries to them fail before connecting
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+	err := wn.Deliver(srv.URL, demostats.MatchSummary{})
+	assert.True(t, errors.Is(err, errRemoteForbidden), "got %v", err)
+	assert.Equal(t, 0, calls)
+}
@@ -1,14 +1,17 @@
 module github.com/martig3/csgo-demo-stats
 
 // +heroku goVersion go1.13
+// +heroku install ./cmd/...
 go 1.13
 
 require (
 	github.com/gin-gonic/gin v1.7.7
 	github.com/markus-wa/demoinfocs-golang/v2 v2.10.1
+	github.com/prometheus/client_golang v1.12.0
 	github.com/sirupsen/logrus v1.8.1
-	github.com/stretchr/testify v1.6.1
-	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
+	github.com/stretchr/testify v1.7.0
+	github.com/xuri/excelize/v2 v2.4.1
+	google.golang.org/grpc v1.43.0
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
@@ -0,0 +1,1964 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: demostats.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ParseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Next chunk of the demo file
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ParseRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type ParseRemoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Full Authorization header sent to the remote url
+	Auth string `protobuf:"bytes,2,opt,name=auth,proto3" json:"auth,omitempty"`
+}
+
+func (x *ParseRemoteRequest) Reset() {
+	*x = ParseRemoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseRemoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRemoteRequest) ProtoMessage() {}
+
+func (x *ParseRemoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRemoteRequest.ProtoReflect.Descriptor instead.
+func (*ParseRemoteRequest) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ParseRemoteRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ParseRemoteRequest) GetAuth() string {
+	if x != nil {
+		return x.Auth
+	}
+	return ""
+}
+
+// Match mirrors InfoStruct
+type Match struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MatchId    string              `protobuf:"bytes,1,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	MatchValid bool                `protobuf:"varint,2,opt,name=match_valid,json=matchValid,proto3" json:"match_valid,omitempty"`
+	General    *ScoreboardGeneral  `protobuf:"bytes,3,opt,name=general,proto3" json:"general,omitempty"`
+	Players    []*ScoreboardPlayer `protobuf:"bytes,4,rep,name=players,proto3" json:"players,omitempty"`
+	Rounds     []*ScoreboardRound  `protobuf:"bytes,5,rep,name=rounds,proto3" json:"rounds,omitempty"`
+	// Version of the result schema, see demostats.SchemaVersion
+	SchemaVersion int32 `protobuf:"varint,6,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// Problems the parse recovered from
+	Warnings []string `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Errors of event handlers, the stats may be incomplete
+	Errors []string `protobuf:"bytes,8,rep,name=errors,proto3" json:"errors,omitempty"`
+	// Recorded chat, only if requested
+	Chat []*ChatMessage `protobuf:"bytes,9,rep,name=chat,proto3" json:"chat,omitempty"`
+	// Players connecting and disconnecting after the match started
+	Connections []*Connection `protobuf:"bytes,10,rep,name=connections,proto3" json:"connections,omitempty"`
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Match) GetMatchId() string {
+	if x != nil {
+		return x.MatchId
+	}
+	return ""
+}
+
+func (x *Match) GetMatchValid() bool {
+	if x != nil {
+		return x.MatchValid
+	}
+	return false
+}
+
+func (x *Match) GetGeneral() *ScoreboardGeneral {
+	if x != nil {
+		return x.General
+	}
+	return nil
+}
+
+func (x *Match) GetPlayers() []*ScoreboardPlayer {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *Match) GetRounds() []*ScoreboardRound {
+	if x != nil {
+		return x.Rounds
+	}
+	return nil
+}
+
+func (x *Match) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Match) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Match) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Match) GetChat() []*ChatMessage {
+	if x != nil {
+		return x.Chat
+	}
+	return nil
+}
+
+func (x *Match) GetConnections() []*Connection {
+	if x != nil {
+		return x.Connections
+	}
+	return nil
+}
+
+type ScoreboardGeneral struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Winner     int32  `protobuf:"varint,1,opt,name=winner,proto3" json:"winner,omitempty"`
+	ScoreA     int32  `protobuf:"varint,2,opt,name=score_a,json=scoreA,proto3" json:"score_a,omitempty"`
+	ScoreB     int32  `protobuf:"varint,3,opt,name=score_b,json=scoreB,proto3" json:"score_b,omitempty"`
+	MapName    string `protobuf:"bytes,4,opt,name=map_name,json=mapName,proto3" json:"map_name,omitempty"`
+	MapIconUrl string `protobuf:"bytes,5,opt,name=map_icon_url,json=mapIconUrl,proto3" json:"map_icon_url,omitempty"`
+	// Unix time in seconds
+	MatchTime       int64  `protobuf:"varint,6,opt,name=match_time,json=matchTime,proto3" json:"match_time,omitempty"`
+	MatchDurationNs int64  `protobuf:"varint,7,opt,name=match_duration_ns,json=matchDurationNs,proto3" json:"match_duration_ns,omitempty"`
+	DemoLinkUrl     string `protobuf:"bytes,8,opt,name=demo_link_url,json=demoLinkUrl,proto3" json:"demo_link_url,omitempty"`
+	// Score of each half of regulation and of each overtime
+	Halves    []*PeriodScore `protobuf:"bytes,9,rep,name=halves,proto3" json:"halves,omitempty"`
+	Overtimes []*PeriodScore `protobuf:"bytes,10,rep,name=overtimes,proto3" json:"overtimes,omitempty"`
+	// Game mode the match was played in, see demostats.GameMode
+	GameMode string `protobuf:"bytes,11,opt,name=game_mode,json=gameMode,proto3" json:"game_mode,omitempty"`
+	// Rounds of regulation time
+	MaxRounds int32 `protobuf:"varint,12,opt,name=max_rounds,json=maxRounds,proto3" json:"max_rounds,omitempty"`
+	// Demo header, see demostats.ScoreboardGeneral
+	ServerName string `protobuf:"bytes,13,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	ClientName string `protobuf:"bytes,14,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	// gotv or pov
+	DemoType       string  `protobuf:"bytes,15,opt,name=demo_type,json=demoType,proto3" json:"demo_type,omitempty"`
+	TickRate       float64 `protobuf:"fixed64,16,opt,name=tick_rate,json=tickRate,proto3" json:"tick_rate,omitempty"`
+	PlaybackTicks  int32   `protobuf:"varint,17,opt,name=playback_ticks,json=playbackTicks,proto3" json:"playback_ticks,omitempty"`
+	PlaybackFrames int32   `protobuf:"varint,18,opt,name=playback_frames,json=playbackFrames,proto3" json:"playback_frames,omitempty"`
+	DemoDurationNs int64   `protobuf:"varint,19,opt,name=demo_duration_ns,json=demoDurationNs,proto3" json:"demo_duration_ns,omitempty"`
+}
+
+func (x *ScoreboardGeneral) Reset() {
+	*x = ScoreboardGeneral{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreboardGeneral) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreboardGeneral) ProtoMessage() {}
+
+func (x *ScoreboardGeneral) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreboardGeneral.ProtoReflect.Descriptor instead.
+func (*ScoreboardGeneral) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoreboardGeneral) GetWinner() int32 {
+	if x != nil {
+		return x.Winner
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetScoreA() int32 {
+	if x != nil {
+		return x.ScoreA
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetScoreB() int32 {
+	if x != nil {
+		return x.ScoreB
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetMapName() string {
+	if x != nil {
+		return x.MapName
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetMapIconUrl() string {
+	if x != nil {
+		return x.MapIconUrl
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetMatchTime() int64 {
+	if x != nil {
+		return x.MatchTime
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetMatchDurationNs() int64 {
+	if x != nil {
+		return x.MatchDurationNs
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetDemoLinkUrl() string {
+	if x != nil {
+		return x.DemoLinkUrl
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetHalves() []*PeriodScore {
+	if x != nil {
+		return x.Halves
+	}
+	return nil
+}
+
+func (x *ScoreboardGeneral) GetOvertimes() []*PeriodScore {
+	if x != nil {
+		return x.Overtimes
+	}
+	return nil
+}
+
+func (x *ScoreboardGeneral) GetGameMode() string {
+	if x != nil {
+		return x.GameMode
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetMaxRounds() int32 {
+	if x != nil {
+		return x.MaxRounds
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetDemoType() string {
+	if x != nil {
+		return x.DemoType
+	}
+	return ""
+}
+
+func (x *ScoreboardGeneral) GetTickRate() float64 {
+	if x != nil {
+		return x.TickRate
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetPlaybackTicks() int32 {
+	if x != nil {
+		return x.PlaybackTicks
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetPlaybackFrames() int32 {
+	if x != nil {
+		return x.PlaybackFrames
+	}
+	return 0
+}
+
+func (x *ScoreboardGeneral) GetDemoDurationNs() int64 {
+	if x != nil {
+		return x.DemoDurationNs
+	}
+	return 0
+}
+
+// PeriodScore holds the rounds won by each team in a half or an overtime
+type PeriodScore struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ScoreA int32 `protobuf:"varint,1,opt,name=score_a,json=scoreA,proto3" json:"score_a,omitempty"`
+	ScoreB int32 `protobuf:"varint,2,opt,name=score_b,json=scoreB,proto3" json:"score_b,omitempty"`
+}
+
+func (x *PeriodScore) Reset() {
+	*x = PeriodScore{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeriodScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeriodScore) ProtoMessage() {}
+
+func (x *PeriodScore) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeriodScore.ProtoReflect.Descriptor instead.
+func (*PeriodScore) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PeriodScore) GetScoreA() int32 {
+	if x != nil {
+		return x.ScoreA
+	}
+	return 0
+}
+
+func (x *PeriodScore) GetScoreB() int32 {
+	if x != nil {
+		return x.ScoreB
+	}
+	return 0
+}
+
+// WeaponStat holds the stats of one player with one weapon
+type WeaponStat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// demoinfocs EquipmentType
+	Weapon     int32  `protobuf:"varint,1,opt,name=weapon,proto3" json:"weapon,omitempty"`
+	WeaponName string `protobuf:"bytes,2,opt,name=weapon_name,json=weaponName,proto3" json:"weapon_name,omitempty"`
+	Kills      int32  `protobuf:"varint,3,opt,name=kills,proto3" json:"kills,omitempty"`
+	Headshots  int32  `protobuf:"varint,4,opt,name=headshots,proto3" json:"headshots,omitempty"`
+	Accuracy   int32  `protobuf:"varint,5,opt,name=accuracy,proto3" json:"accuracy,omitempty"`
+	Damage     int32  `protobuf:"varint,6,opt,name=damage,proto3" json:"damage,omitempty"`
+	Shots      int32  `protobuf:"varint,7,opt,name=shots,proto3" json:"shots,omitempty"`
+	Hits       int32  `protobuf:"varint,8,opt,name=hits,proto3" json:"hits,omitempty"`
+}
+
+func (x *WeaponStat) Reset() {
+	*x = WeaponStat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WeaponStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeaponStat) ProtoMessage() {}
+
+func (x *WeaponStat) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeaponStat.ProtoReflect.Descriptor instead.
+func (*WeaponStat) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WeaponStat) GetWeapon() int32 {
+	if x != nil {
+		return x.Weapon
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetWeaponName() string {
+	if x != nil {
+		return x.WeaponName
+	}
+	return ""
+}
+
+func (x *WeaponStat) GetKills() int32 {
+	if x != nil {
+		return x.Kills
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetHeadshots() int32 {
+	if x != nil {
+		return x.Headshots
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetAccuracy() int32 {
+	if x != nil {
+		return x.Accuracy
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetDamage() int32 {
+	if x != nil {
+		return x.Damage
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetShots() int32 {
+	if x != nil {
+		return x.Shots
+	}
+	return 0
+}
+
+func (x *WeaponStat) GetHits() int32 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+type ScoreboardPlayer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsBot            bool          `protobuf:"varint,1,opt,name=is_bot,json=isBot,proto3" json:"is_bot,omitempty"`
+	IsAMember        bool          `protobuf:"varint,2,opt,name=is_a_member,json=isAMember,proto3" json:"is_a_member,omitempty"`
+	Team             string        `protobuf:"bytes,3,opt,name=team,proto3" json:"team,omitempty"`
+	Steamid          string        `protobuf:"bytes,4,opt,name=steamid,proto3" json:"steamid,omitempty"`
+	Steamid64        uint64        `protobuf:"varint,5,opt,name=steamid64,proto3" json:"steamid64,omitempty"`
+	Name             string        `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Atag             string        `protobuf:"bytes,7,opt,name=atag,proto3" json:"atag,omitempty"`
+	Rank             int32         `protobuf:"varint,8,opt,name=rank,proto3" json:"rank,omitempty"`
+	Kills            int32         `protobuf:"varint,9,opt,name=kills,proto3" json:"kills,omitempty"`
+	Mvps             int32         `protobuf:"varint,10,opt,name=mvps,proto3" json:"mvps,omitempty"`
+	Deaths           int32         `protobuf:"varint,11,opt,name=deaths,proto3" json:"deaths,omitempty"`
+	Assists          int32         `protobuf:"varint,12,opt,name=assists,proto3" json:"assists,omitempty"`
+	Kd               float64       `protobuf:"fixed64,13,opt,name=kd,proto3" json:"kd,omitempty"`
+	Adr              float64       `protobuf:"fixed64,14,opt,name=adr,proto3" json:"adr,omitempty"`
+	Kast             float64       `protobuf:"fixed64,15,opt,name=kast,proto3" json:"kast,omitempty"`
+	KastRounds       int32         `protobuf:"varint,16,opt,name=kast_rounds,json=kastRounds,proto3" json:"kast_rounds,omitempty"`
+	Rws              float64       `protobuf:"fixed64,17,opt,name=rws,proto3" json:"rws,omitempty"`
+	Rating           float64       `protobuf:"fixed64,18,opt,name=rating,proto3" json:"rating,omitempty"`
+	Headshots        int32         `protobuf:"varint,19,opt,name=headshots,proto3" json:"headshots,omitempty"`
+	Hsprecent        float64       `protobuf:"fixed64,20,opt,name=hsprecent,proto3" json:"hsprecent,omitempty"`
+	Firstkills       int32         `protobuf:"varint,21,opt,name=firstkills,proto3" json:"firstkills,omitempty"`
+	Firstdeaths      int32         `protobuf:"varint,22,opt,name=firstdeaths,proto3" json:"firstdeaths,omitempty"`
+	Tradekills       int32         `protobuf:"varint,23,opt,name=tradekills,proto3" json:"tradekills,omitempty"`
+	Tradedeaths      int32         `protobuf:"varint,24,opt,name=tradedeaths,proto3" json:"tradedeaths,omitempty"`
+	Tradefirstkills  int32         `protobuf:"varint,25,opt,name=tradefirstkills,proto3" json:"tradefirstkills,omitempty"`
+	Tradefirstdeaths int32         `protobuf:"varint,26,opt,name=tradefirstdeaths,proto3" json:"tradefirstdeaths,omitempty"`
+	Roundswonv5      int32         `protobuf:"varint,27,opt,name=roundswonv5,proto3" json:"roundswonv5,omitempty"`
+	Roundswonv4      int32         `protobuf:"varint,28,opt,name=roundswonv4,proto3" json:"roundswonv4,omitempty"`
+	Roundswonv3      int32         `protobuf:"varint,29,opt,name=roundswonv3,proto3" json:"roundswonv3,omitempty"`
+	Rounds5K         int32         `protobuf:"varint,30,opt,name=rounds5k,proto3" json:"rounds5k,omitempty"`
+	Rounds4K         int32         `protobuf:"varint,31,opt,name=rounds4k,proto3" json:"rounds4k,omitempty"`
+	Rounds3K         int32         `protobuf:"varint,32,opt,name=rounds3k,proto3" json:"rounds3k,omitempty"`
+	Rounds2K         int32         `protobuf:"varint,33,opt,name=rounds2k,proto3" json:"rounds2k,omitempty"`
+	Rounds1K         int32         `protobuf:"varint,34,opt,name=rounds1k,proto3" json:"rounds1k,omitempty"`
+	EffFlashes       int32         `protobuf:"varint,35,opt,name=eff_flashes,json=effFlashes,proto3" json:"eff_flashes,omitempty"`
+	Efpr             float64       `protobuf:"fixed64,36,opt,name=efpr,proto3" json:"efpr,omitempty"`
+	FlashDurationMs  int64         `protobuf:"varint,37,opt,name=flash_duration_ms,json=flashDurationMs,proto3" json:"flash_duration_ms,omitempty"`
+	WeaponStats      []*WeaponStat `protobuf:"bytes,38,rep,name=weapon_stats,json=weaponStats,proto3" json:"weapon_stats,omitempty"`
+	// Damage dealt to other players by their steamid64
+	PlayerDamages map[uint64]int32 `protobuf:"bytes,39,rep,name=player_damages,json=playerDamages,proto3" json:"player_damages,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// Kills per round played
+	Kpr float64 `protobuf:"fixed64,40,opt,name=kpr,proto3" json:"kpr,omitempty"`
+	// Rounds the player took part in, the averages are taken over them
+	RoundsPlayed int32 `protobuf:"varint,41,opt,name=rounds_played,json=roundsPlayed,proto3" json:"rounds_played,omitempty"`
+	// Joined after the match started
+	Substitute  bool  `protobuf:"varint,42,opt,name=substitute,proto3" json:"substitute,omitempty"`
+	Roundswonv2 int32 `protobuf:"varint,43,opt,name=roundswonv2,proto3" json:"roundswonv2,omitempty"`
+}
+
+func (x *ScoreboardPlayer) Reset() {
+	*x = ScoreboardPlayer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreboardPlayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreboardPlayer) ProtoMessage() {}
+
+func (x *ScoreboardPlayer) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreboardPlayer.ProtoReflect.Descriptor instead.
+func (*ScoreboardPlayer) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ScoreboardPlayer) GetIsBot() bool {
+	if x != nil {
+		return x.IsBot
+	}
+	return false
+}
+
+func (x *ScoreboardPlayer) GetIsAMember() bool {
+	if x != nil {
+		return x.IsAMember
+	}
+	return false
+}
+
+func (x *ScoreboardPlayer) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *ScoreboardPlayer) GetSteamid() string {
+	if x != nil {
+		return x.Steamid
+	}
+	return ""
+}
+
+func (x *ScoreboardPlayer) GetSteamid64() uint64 {
+	if x != nil {
+		return x.Steamid64
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScoreboardPlayer) GetAtag() string {
+	if x != nil {
+		return x.Atag
+	}
+	return ""
+}
+
+func (x *ScoreboardPlayer) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetKills() int32 {
+	if x != nil {
+		return x.Kills
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetMvps() int32 {
+	if x != nil {
+		return x.Mvps
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetDeaths() int32 {
+	if x != nil {
+		return x.Deaths
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetAssists() int32 {
+	if x != nil {
+		return x.Assists
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetKd() float64 {
+	if x != nil {
+		return x.Kd
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetAdr() float64 {
+	if x != nil {
+		return x.Adr
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetKast() float64 {
+	if x != nil {
+		return x.Kast
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetKastRounds() int32 {
+	if x != nil {
+		return x.KastRounds
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRws() float64 {
+	if x != nil {
+		return x.Rws
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetHeadshots() int32 {
+	if x != nil {
+		return x.Headshots
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetHsprecent() float64 {
+	if x != nil {
+		return x.Hsprecent
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetFirstkills() int32 {
+	if x != nil {
+		return x.Firstkills
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetFirstdeaths() int32 {
+	if x != nil {
+		return x.Firstdeaths
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetTradekills() int32 {
+	if x != nil {
+		return x.Tradekills
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetTradedeaths() int32 {
+	if x != nil {
+		return x.Tradedeaths
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetTradefirstkills() int32 {
+	if x != nil {
+		return x.Tradefirstkills
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetTradefirstdeaths() int32 {
+	if x != nil {
+		return x.Tradefirstdeaths
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRoundswonv5() int32 {
+	if x != nil {
+		return x.Roundswonv5
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRoundswonv4() int32 {
+	if x != nil {
+		return x.Roundswonv4
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRoundswonv3() int32 {
+	if x != nil {
+		return x.Roundswonv3
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRounds5K() int32 {
+	if x != nil {
+		return x.Rounds5K
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRounds4K() int32 {
+	if x != nil {
+		return x.Rounds4K
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRounds3K() int32 {
+	if x != nil {
+		return x.Rounds3K
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRounds2K() int32 {
+	if x != nil {
+		return x.Rounds2K
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRounds1K() int32 {
+	if x != nil {
+		return x.Rounds1K
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetEffFlashes() int32 {
+	if x != nil {
+		return x.EffFlashes
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetEfpr() float64 {
+	if x != nil {
+		return x.Efpr
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetFlashDurationMs() int64 {
+	if x != nil {
+		return x.FlashDurationMs
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetWeaponStats() []*WeaponStat {
+	if x != nil {
+		return x.WeaponStats
+	}
+	return nil
+}
+
+func (x *ScoreboardPlayer) GetPlayerDamages() map[uint64]int32 {
+	if x != nil {
+		return x.PlayerDamages
+	}
+	return nil
+}
+
+func (x *ScoreboardPlayer) GetKpr() float64 {
+	if x != nil {
+		return x.Kpr
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetRoundsPlayed() int32 {
+	if x != nil {
+		return x.RoundsPlayed
+	}
+	return 0
+}
+
+func (x *ScoreboardPlayer) GetSubstitute() bool {
+	if x != nil {
+		return x.Substitute
+	}
+	return false
+}
+
+func (x *ScoreboardPlayer) GetRoundswonv2() int32 {
+	if x != nil {
+		return x.Roundswonv2
+	}
+	return 0
+}
+
+// RoundKill references players by their steamid64
+type RoundKill struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TimeNs     int64  `protobuf:"varint,1,opt,name=time_ns,json=timeNs,proto3" json:"time_ns,omitempty"`
+	IsHeadshot bool   `protobuf:"varint,2,opt,name=is_headshot,json=isHeadshot,proto3" json:"is_headshot,omitempty"`
+	Victim     uint64 `protobuf:"varint,3,opt,name=victim,proto3" json:"victim,omitempty"`
+	Killer     uint64 `protobuf:"varint,4,opt,name=killer,proto3" json:"killer,omitempty"`
+	Assister   uint64 `protobuf:"varint,5,opt,name=assister,proto3" json:"assister,omitempty"`
+	Weapon     int32  `protobuf:"varint,6,opt,name=weapon,proto3" json:"weapon,omitempty"`
+	WeaponName string `protobuf:"bytes,7,opt,name=weapon_name,json=weaponName,proto3" json:"weapon_name,omitempty"`
+	// The killer is a human controlling a bot
+	BotControlled bool `protobuf:"varint,8,opt,name=bot_controlled,json=botControlled,proto3" json:"bot_controlled,omitempty"`
+}
+
+func (x *RoundKill) Reset() {
+	*x = RoundKill{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundKill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundKill) ProtoMessage() {}
+
+func (x *RoundKill) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundKill.ProtoReflect.Descriptor instead.
+func (*RoundKill) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RoundKill) GetTimeNs() int64 {
+	if x != nil {
+		return x.TimeNs
+	}
+	return 0
+}
+
+func (x *RoundKill) GetIsHeadshot() bool {
+	if x != nil {
+		return x.IsHeadshot
+	}
+	return false
+}
+
+func (x *RoundKill) GetVictim() uint64 {
+	if x != nil {
+		return x.Victim
+	}
+	return 0
+}
+
+func (x *RoundKill) GetKiller() uint64 {
+	if x != nil {
+		return x.Killer
+	}
+	return 0
+}
+
+func (x *RoundKill) GetAssister() uint64 {
+	if x != nil {
+		return x.Assister
+	}
+	return 0
+}
+
+func (x *RoundKill) GetWeapon() int32 {
+	if x != nil {
+		return x.Weapon
+	}
+	return 0
+}
+
+func (x *RoundKill) GetWeaponName() string {
+	if x != nil {
+		return x.WeaponName
+	}
+	return ""
+}
+
+func (x *RoundKill) GetBotControlled() bool {
+	if x != nil {
+		return x.BotControlled
+	}
+	return false
+}
+
+type ScoreboardRound struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AWonRound        bool         `protobuf:"varint,1,opt,name=a_won_round,json=aWonRound,proto3" json:"a_won_round,omitempty"`
+	DurationNs       int64        `protobuf:"varint,2,opt,name=duration_ns,json=durationNs,proto3" json:"duration_ns,omitempty"`
+	KillsA           []*RoundKill `protobuf:"bytes,3,rep,name=kills_a,json=killsA,proto3" json:"kills_a,omitempty"`
+	KillsB           []*RoundKill `protobuf:"bytes,4,rep,name=kills_b,json=killsB,proto3" json:"kills_b,omitempty"`
+	ScoreA           int32        `protobuf:"varint,5,opt,name=score_a,json=scoreA,proto3" json:"score_a,omitempty"`
+	ScoreB           int32        `protobuf:"varint,6,opt,name=score_b,json=scoreB,proto3" json:"score_b,omitempty"`
+	SurvivorsA       int32        `protobuf:"varint,7,opt,name=survivors_a,json=survivorsA,proto3" json:"survivors_a,omitempty"`
+	SurvivorsB       int32        `protobuf:"varint,8,opt,name=survivors_b,json=survivorsB,proto3" json:"survivors_b,omitempty"`
+	TeamWon          int32        `protobuf:"varint,9,opt,name=team_won,json=teamWon,proto3" json:"team_won,omitempty"`
+	TotalDamageGiven int32        `protobuf:"varint,10,opt,name=total_damage_given,json=totalDamageGiven,proto3" json:"total_damage_given,omitempty"`
+	TotalDamageTaken int32        `protobuf:"varint,11,opt,name=total_damage_taken,json=totalDamageTaken,proto3" json:"total_damage_taken,omitempty"`
+	WinReason        int32        `protobuf:"varint,12,opt,name=win_reason,json=winReason,proto3" json:"win_reason,omitempty"`
+	WinnerTeam       int32        `protobuf:"varint,13,opt,name=winner_team,json=winnerTeam,proto3" json:"winner_team,omitempty"`
+	BombPlanter      uint64       `protobuf:"varint,14,opt,name=bomb_planter,json=bombPlanter,proto3" json:"bomb_planter,omitempty"`
+	BombDefuser      uint64       `protobuf:"varint,15,opt,name=bomb_defuser,json=bombDefuser,proto3" json:"bomb_defuser,omitempty"`
+	// The demo ended before the round, it is left out of the stats
+	Incomplete bool `protobuf:"varint,16,opt,name=incomplete,proto3" json:"incomplete,omitempty"`
+	// Half the round was played in, every overtime has two halves as well
+	Half int32 `protobuf:"varint,17,opt,name=half,proto3" json:"half,omitempty"`
+	// Overtime the round was played in, 0 in regulation
+	Overtime int32 `protobuf:"varint,18,opt,name=overtime,proto3" json:"overtime,omitempty"`
+	// Knife round left out of the stats
+	Knife bool `protobuf:"varint,19,opt,name=knife,proto3" json:"knife,omitempty"`
+}
+
+func (x *ScoreboardRound) Reset() {
+	*x = ScoreboardRound{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreboardRound) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreboardRound) ProtoMessage() {}
+
+func (x *ScoreboardRound) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreboardRound.ProtoReflect.Descriptor instead.
+func (*ScoreboardRound) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScoreboardRound) GetAWonRound() bool {
+	if x != nil {
+		return x.AWonRound
+	}
+	return false
+}
+
+func (x *ScoreboardRound) GetDurationNs() int64 {
+	if x != nil {
+		return x.DurationNs
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetKillsA() []*RoundKill {
+	if x != nil {
+		return x.KillsA
+	}
+	return nil
+}
+
+func (x *ScoreboardRound) GetKillsB() []*RoundKill {
+	if x != nil {
+		return x.KillsB
+	}
+	return nil
+}
+
+func (x *ScoreboardRound) GetScoreA() int32 {
+	if x != nil {
+		return x.ScoreA
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetScoreB() int32 {
+	if x != nil {
+		return x.ScoreB
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetSurvivorsA() int32 {
+	if x != nil {
+		return x.SurvivorsA
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetSurvivorsB() int32 {
+	if x != nil {
+		return x.SurvivorsB
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetTeamWon() int32 {
+	if x != nil {
+		return x.TeamWon
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetTotalDamageGiven() int32 {
+	if x != nil {
+		return x.TotalDamageGiven
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetTotalDamageTaken() int32 {
+	if x != nil {
+		return x.TotalDamageTaken
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetWinReason() int32 {
+	if x != nil {
+		return x.WinReason
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetWinnerTeam() int32 {
+	if x != nil {
+		return x.WinnerTeam
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetBombPlanter() uint64 {
+	if x != nil {
+		return x.BombPlanter
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetBombDefuser() uint64 {
+	if x != nil {
+		return x.BombDefuser
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+func (x *ScoreboardRound) GetHalf() int32 {
+	if x != nil {
+		return x.Half
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetOvertime() int32 {
+	if x != nil {
+		return x.Overtime
+	}
+	return 0
+}
+
+func (x *ScoreboardRound) GetKnife() bool {
+	if x != nil {
+		return x.Knife
+	}
+	return false
+}
+
+type Connection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round int32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// Time since the start of the demo
+	TimeNs    int64  `protobuf:"varint,2,opt,name=time_ns,json=timeNs,proto3" json:"time_ns,omitempty"`
+	Steamid64 uint64 `protobuf:"varint,3,opt,name=steamid64,proto3" json:"steamid64,omitempty"`
+	Name      string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Connected bool   `protobuf:"varint,5,opt,name=connected,proto3" json:"connected,omitempty"`
+}
+
+func (x *Connection) Reset() {
+	*x = Connection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Connection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Connection) ProtoMessage() {}
+
+func (x *Connection) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Connection.ProtoReflect.Descriptor instead.
+func (*Connection) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Connection) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Connection) GetTimeNs() int64 {
+	if x != nil {
+		return x.TimeNs
+	}
+	return 0
+}
+
+func (x *Connection) GetSteamid64() uint64 {
+	if x != nil {
+		return x.Steamid64
+	}
+	return 0
+}
+
+func (x *Connection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Connection) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+type ChatMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round int32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// Time since the start of the demo
+	TimeNs    int64  `protobuf:"varint,2,opt,name=time_ns,json=timeNs,proto3" json:"time_ns,omitempty"`
+	Steamid64 uint64 `protobuf:"varint,3,opt,name=steamid64,proto3" json:"steamid64,omitempty"`
+	Name      string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Team      string `protobuf:"bytes,5,opt,name=team,proto3" json:"team,omitempty"`
+	AllChat   bool   `protobuf:"varint,6,opt,name=all_chat,json=allChat,proto3" json:"all_chat,omitempty"`
+	Text      string `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"`
+	// Words of the word list found in the text
+	Matches []string `protobuf:"bytes,8,rep,name=matches,proto3" json:"matches,omitempty"`
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_demostats_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_demostats_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_demostats_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ChatMessage) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetTimeNs() int64 {
+	if x != nil {
+		return x.TimeNs
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetSteamid64() uint64 {
+	if x != nil {
+		return x.Steamid64
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetAllChat() bool {
+	if x != nil {
+		return x.AllChat
+	}
+	return false
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetMatches() []string {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+var File_demostats_proto protoreflect.FileDescriptor
+
+var file_demostats_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22, 0x24, 0x0a, 0x0c,
+	0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x63, 0x68, 0x75,
+	0x6e, 0x6b, 0x22, 0x3a, 0x0a, 0x12, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0xa6,
+	0x03, 0x0a, 0x05, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x12, 0x36, 0x0a, 0x07, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x6c, 0x52, 0x07, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x35, 0x0a, 0x07,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x52,
+	0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x63, 0x68, 0x61, 0x74, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x43, 0x68, 0x61,
+	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x04, 0x63, 0x68, 0x61, 0x74, 0x12, 0x37,
+	0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xa1, 0x05, 0x0a, 0x11, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x16, 0x0a,
+	0x06, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77,
+	0x69, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x5f, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x41, 0x12, 0x17,
+	0x0a, 0x07, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x5f, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x42, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x70, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x70, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0c, 0x6d, 0x61, 0x70, 0x5f, 0x69, 0x63, 0x6f, 0x6e, 0x5f, 0x75,
+	0x72, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x61, 0x70, 0x49, 0x63, 0x6f,
+	0x6e, 0x55, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x73, 0x12,
+	0x22, 0x0a, 0x0d, 0x64, 0x65, 0x6d, 0x6f, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x6d, 0x6f, 0x4c, 0x69, 0x6e, 0x6b,
+	0x55, 0x72, 0x6c, 0x12, 0x2e, 0x0a, 0x06, 0x68, 0x61, 0x6c, 0x76, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e,
+	0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x06, 0x68, 0x61, 0x6c,
+	0x76, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x09, 0x6f, 0x76, 0x65, 0x72, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x2e, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x09,
+	0x6f, 0x76, 0x65, 0x72, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x67, 0x61,
+	0x6d, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x6d, 0x6f, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x6d, 0x6f,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x63, 0x6b, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x74, 0x69, 0x63, 0x6b, 0x52, 0x61, 0x74,
+	0x65, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x74, 0x69,
+	0x63, 0x6b, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x70, 0x6c, 0x61, 0x79, 0x62,
+	0x61, 0x63, 0x6b, 0x54, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x6c, 0x61, 0x79,
+	0x62, 0x61, 0x63, 0x6b, 0x5f, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0e, 0x70, 0x6c, 0x61, 0x79, 0x62, 0x61, 0x63, 0x6b, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x73, 0x12, 0x28, 0x0a, 0x10, 0x64, 0x65, 0x6d, 0x6f, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x6e, 0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x64, 0x65, 0x6d,
+	0x6f, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x73, 0x22, 0x3f, 0x0a, 0x0b, 0x50,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x5f, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x41, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x5f, 0x62, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x42, 0x22, 0xd7, 0x01, 0x0a,
+	0x0a, 0x57, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x77,
+	0x65, 0x61, 0x70, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x61,
+	0x70, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x65, 0x61, 0x70, 0x6f, 0x6e,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x65,
+	0x61, 0x64, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x68,
+	0x65, 0x61, 0x64, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75,
+	0x72, 0x61, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75,
+	0x72, 0x61, 0x63, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x64, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x68, 0x6f,
+	0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x22, 0xe3, 0x0a, 0x0a, 0x10, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x12, 0x15, 0x0a, 0x06, 0x69,
+	0x73, 0x5f, 0x62, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x73, 0x42,
+	0x6f, 0x74, 0x12, 0x1e, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x61, 0x5f, 0x6d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x41, 0x4d, 0x65, 0x6d, 0x62,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x65, 0x61, 0x6d, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x74, 0x65, 0x61, 0x6d, 0x69, 0x64,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x65, 0x61, 0x6d, 0x69, 0x64, 0x36, 0x34, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x74, 0x65, 0x61, 0x6d, 0x69, 0x64, 0x36, 0x34, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x74, 0x61, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x61, 0x74, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x69,
+	0x6c, 0x6c, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6b, 0x69, 0x6c, 0x6c, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x6d, 0x76, 0x70, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x6d, 0x76, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x61, 0x74, 0x68, 0x73, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x64, 0x65, 0x61, 0x74, 0x68, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x61,
+	0x73, 0x73, 0x69, 0x73, 0x74, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x6b, 0x64, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x02, 0x6b, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x64, 0x72, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x03, 0x61, 0x64, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x61, 0x73, 0x74,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6b, 0x61, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6b, 0x61, 0x73, 0x74, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x6b, 0x61, 0x73, 0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x10, 0x0a,
+	0x03, 0x72, 0x77, 0x73, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x72, 0x77, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x65, 0x61, 0x64, 0x73,
+	0x68, 0x6f, 0x74, 0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x68, 0x65, 0x61, 0x64,
+	0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x73, 0x70, 0x72, 0x65, 0x63, 0x65,
+	0x6e, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x68, 0x73, 0x70, 0x72, 0x65, 0x63,
+	0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x6b, 0x69, 0x6c, 0x6c,
+	0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x6b, 0x69,
+	0x6c, 0x6c, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x64, 0x65, 0x61, 0x74,
+	0x68, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x64,
+	0x65, 0x61, 0x74, 0x68, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x64, 0x65, 0x6b, 0x69,
+	0x6c, 0x6c, 0x73, 0x18, 0x17, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x64, 0x65,
+	0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x72, 0x61, 0x64, 0x65, 0x64, 0x65,
+	0x61, 0x74, 0x68, 0x73, 0x18, 0x18, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x72, 0x61, 0x64,
+	0x65, 0x64, 0x65, 0x61, 0x74, 0x68, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x64, 0x65,
+	0x66, 0x69, 0x72, 0x73, 0x74, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0f, 0x74, 0x72, 0x61, 0x64, 0x65, 0x66, 0x69, 0x72, 0x73, 0x74, 0x6b, 0x69, 0x6c, 0x6c,
+	0x73, 0x12, 0x2a, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x64, 0x65, 0x66, 0x69, 0x72, 0x73, 0x74, 0x64,
+	0x65, 0x61, 0x74, 0x68, 0x73, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x74, 0x72, 0x61,
+	0x64, 0x65, 0x66, 0x69, 0x72, 0x73, 0x74, 0x64, 0x65, 0x61, 0x74, 0x68, 0x73, 0x12, 0x20, 0x0a,
+	0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x35, 0x18, 0x1b, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x35, 0x12,
+	0x20, 0x0a, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x34, 0x18, 0x1c,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76,
+	0x34, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x33,
+	0x18, 0x1d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f,
+	0x6e, 0x76, 0x33, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x35, 0x6b, 0x18,
+	0x1e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x35, 0x6b, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x34, 0x6b, 0x18, 0x1f, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x34, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x73, 0x33, 0x6b, 0x18, 0x20, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x73, 0x33, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x73, 0x32, 0x6b, 0x18, 0x21, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x73, 0x32, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x31, 0x6b, 0x18,
+	0x22, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x31, 0x6b, 0x12,
+	0x1f, 0x0a, 0x0b, 0x65, 0x66, 0x66, 0x5f, 0x66, 0x6c, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x23,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x65, 0x66, 0x66, 0x46, 0x6c, 0x61, 0x73, 0x68, 0x65, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x65, 0x66, 0x70, 0x72, 0x18, 0x24, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04,
+	0x65, 0x66, 0x70, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x66, 0x6c, 0x61, 0x73, 0x68, 0x5f, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x25, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x66, 0x6c, 0x61, 0x73, 0x68, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73,
+	0x12, 0x38, 0x0a, 0x0c, 0x77, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x18, 0x26, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x2e, 0x57, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x52, 0x0b, 0x77,
+	0x65, 0x61, 0x70, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x55, 0x0a, 0x0e, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x5f, 0x64, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x18, 0x27, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x53,
+	0x63, 0x6f, 0x72, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x2e,
+	0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0d, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x70, 0x72, 0x18, 0x28, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03,
+	0x6b, 0x70, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x5f, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x64, 0x18, 0x29, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x73,
+	0x74, 0x69, 0x74, 0x75, 0x74, 0x65, 0x18, 0x2a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x75,
+	0x62, 0x73, 0x74, 0x69, 0x74, 0x75, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x32, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x73, 0x77, 0x6f, 0x6e, 0x76, 0x32, 0x1a, 0x40, 0x0a, 0x12, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xf1, 0x01, 0x0a,
+	0x09, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x4b, 0x69, 0x6c, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x69, 0x6d,
+	0x65, 0x4e, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x73, 0x68,
+	0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x48, 0x65, 0x61, 0x64,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6d, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6d, 0x12, 0x16, 0x0a, 0x06,
+	0x6b, 0x69, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6b, 0x69,
+	0x6c, 0x6c, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x77, 0x65, 0x61, 0x70, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x65, 0x61, 0x70,
+	0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77,
+	0x65, 0x61, 0x70, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x62, 0x6f, 0x74,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0d, 0x62, 0x6f, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x64,
+	0x22, 0x87, 0x05, 0x0a, 0x0f, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1e, 0x0a, 0x0b, 0x61, 0x5f, 0x77, 0x6f, 0x6e, 0x5f, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x57, 0x6f, 0x6e, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x4e, 0x73, 0x12, 0x2d, 0x0a, 0x07, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x5f, 0x61,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x4b, 0x69, 0x6c, 0x6c, 0x52, 0x06, 0x6b, 0x69,
+	0x6c, 0x6c, 0x73, 0x41, 0x12, 0x2d, 0x0a, 0x07, 0x6b, 0x69, 0x6c, 0x6c, 0x73, 0x5f, 0x62, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x4b, 0x69, 0x6c, 0x6c, 0x52, 0x06, 0x6b, 0x69, 0x6c,
+	0x6c, 0x73, 0x42, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x5f, 0x61, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x41, 0x12, 0x17, 0x0a, 0x07,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x5f, 0x62, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73,
+	0x63, 0x6f, 0x72, 0x65, 0x42, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76, 0x6f,
+	0x72, 0x73, 0x5f, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x75, 0x72, 0x76,
+	0x69, 0x76, 0x6f, 0x72, 0x73, 0x41, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x75, 0x72, 0x76, 0x69, 0x76,
+	0x6f, 0x72, 0x73, 0x5f, 0x62, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x75, 0x72,
+	0x76, 0x69, 0x76, 0x6f, 0x72, 0x73, 0x42, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x65, 0x61, 0x6d, 0x5f,
+	0x77, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x65, 0x61, 0x6d, 0x57,
+	0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x12, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x6d, 0x61,
+	0x67, 0x65, 0x5f, 0x67, 0x69, 0x76, 0x65, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x47, 0x69, 0x76, 0x65, 0x6e,
+	0x12, 0x2c, 0x0a, 0x12, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x6d, 0x61, 0x67, 0x65,
+	0x5f, 0x74, 0x61, 0x6b, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x44, 0x61, 0x6d, 0x61, 0x67, 0x65, 0x54, 0x61, 0x6b, 0x65, 0x6e, 0x12, 0x1d,
+	0x0a, 0x0a, 0x77, 0x69, 0x6e, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x77, 0x69, 0x6e, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1f, 0x0a,
+	0x0b, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x77, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x54, 0x65, 0x61, 0x6d, 0x12, 0x21,
+	0x0a, 0x0c, 0x62, 0x6f, 0x6d, 0x62, 0x5f, 0x70, 0x6c, 0x61, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6f, 0x6d, 0x62, 0x50, 0x6c, 0x61, 0x6e, 0x74, 0x65,
+	0x72, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x6f, 0x6d, 0x62, 0x5f, 0x64, 0x65, 0x66, 0x75, 0x73, 0x65,
+	0x72, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6f, 0x6d, 0x62, 0x44, 0x65, 0x66,
+	0x75, 0x73, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x74, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x65, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x6c, 0x66, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x68, 0x61, 0x6c, 0x66, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6f, 0x76, 0x65, 0x72,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x6e, 0x69, 0x66, 0x65, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x05, 0x6b, 0x6e, 0x69, 0x66, 0x65, 0x22, 0x8b, 0x01, 0x0a, 0x0a, 0x43,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12,
+	0x17, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x74, 0x69, 0x6d, 0x65, 0x4e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x65, 0x61,
+	0x6d, 0x69, 0x64, 0x36, 0x34, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x74, 0x65,
+	0x61, 0x6d, 0x69, 0x64, 0x36, 0x34, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x22, 0xcb, 0x01, 0x0a, 0x0b, 0x43, 0x68, 0x61,
+	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x17,
+	0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x74, 0x69, 0x6d, 0x65, 0x4e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x65, 0x61, 0x6d,
+	0x69, 0x64, 0x36, 0x34, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x74, 0x65, 0x61,
+	0x6d, 0x69, 0x64, 0x36, 0x34, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x61,
+	0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x12, 0x19, 0x0a,
+	0x08, 0x61, 0x6c, 0x6c, 0x5f, 0x63, 0x68, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x61, 0x6c, 0x6c, 0x43, 0x68, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x32, 0x81, 0x01, 0x0a, 0x09, 0x44, 0x65, 0x6d, 0x6f, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x05, 0x50, 0x61, 0x72, 0x73, 0x65, 0x12, 0x17, 0x2e,
+	0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x28, 0x01, 0x12, 0x3e, 0x0a, 0x0b, 0x50, 0x61,
+	0x72, 0x73, 0x65, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x1d, 0x2e, 0x64, 0x65, 0x6d, 0x6f,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x64, 0x65, 0x6d, 0x6f, 0x73,
+	0x74, 0x61, 0x74, 0x73, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x61, 0x72, 0x74, 0x69, 0x67, 0x33,
+	0x2f, 0x63, 0x73, 0x67, 0x6f, 0x2d, 0x64, 0x65, 0x6d, 0x6f, 0x2d, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_demostats_proto_rawDescOnce sync.Once
+	file_demostats_proto_rawDescData = file_demostats_proto_rawDesc
+)
+
+func file_demostats_proto_rawDescGZIP() []byte {
+	file_demostats_proto_rawDescOnce.Do(func() {
+		file_demostats_proto_rawDescData = protoimpl.X.CompressGZIP(file_demostats_proto_rawDescData)
+	})
+	return file_demostats_proto_rawDescData
+}
+
+var file_demostats_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_demostats_proto_goTypes = []interface{}{
+	(*ParseRequest)(nil),       // 0: demostats.ParseRequest
+	(*ParseRemoteRequest)(nil), // 1: demostats.ParseRemoteRequest
+	(*Match)(nil),              // 2: demostats.Match
+	(*ScoreboardGeneral)(nil),  // 3: demostats.ScoreboardGeneral
+	(*PeriodScore)(nil),        // 4: demostats.PeriodScore
+	(*WeaponStat)(nil),         // 5: demostats.WeaponStat
+	(*ScoreboardPlayer)(nil),   // 6: demostats.ScoreboardPlayer
+	(*RoundKill)(nil),          // 7: demostats.RoundKill
+	(*ScoreboardRound)(nil),    // 8: demostats.ScoreboardRound
+	(*Connection)(nil),         // 9: demostats.Connection
+	(*ChatMessage)(nil),        // 10: demostats.ChatMessage
+	nil,                        // 11: demostats.ScoreboardPlayer.PlayerDamagesEntry
+}
+var file_demostats_proto_depIdxs = []int32{
+	3,  // 0: demostats.Match.general:type_name -> demostats.ScoreboardGeneral
+	6,  // 1: demostats.Match.players:type_name -> demostats.ScoreboardPlayer
+	8,  // 2: demostats.Match.rounds:type_name -> demostats.ScoreboardRound
+	10, // 3: demostats.Match.chat:type_name -> demostats.ChatMessage
+	9,  // 4: demostats.Match.connections:type_name -> demostats.Connection
+	4,  // 5: demostats.ScoreboardGeneral.halves:type_name -> demostats.PeriodScore
+	4,  // 6: demostats.ScoreboardGeneral.overtimes:type_name -> demostats.PeriodScore
+	5,  // 7: demostats.ScoreboardPlayer.weapon_stats:type_name -> demostats.WeaponStat
+	11, // 8: demostats.ScoreboardPlayer.player_damages:type_name -> demostats.ScoreboardPlayer.PlayerDamagesEntry
+	7,  // 9: demostats.ScoreboardRound.kills_a:type_name -> demostats.RoundKill
+	7,  // 10: demostats.ScoreboardRound.kills_b:type_name -> demostats.RoundKill
+	0,  // 11: demostats.DemoStats.Parse:input_type -> demostats.ParseRequest
+	1,  // 12: demostats.DemoStats.ParseRemote:input_type -> demostats.ParseRemoteRequest
+	2,  // 13: demostats.DemoStats.Parse:output_type -> demostats.Match
+	2,  // 14: demostats.DemoStats.ParseRemote:output_type -> demostats.Match
+	13, // [13:15] is the sub-list for method output_type
+	11, // [11:13] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_demostats_proto_init() }
+func file_demostats_proto_init() {
+	if File_demostats_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_demostats_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseRemoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Match); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreboardGeneral); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PeriodScore); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WeaponStat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreboardPlayer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundKill); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreboardRound); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Connection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_demostats_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChatMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_demostats_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_demostats_proto_goTypes,
+		DependencyIndexes: file_demostats_proto_depIdxs,
+		MessageInfos:      file_demostats_proto_msgTypes,
+	}.Build()
+	File_demostats_proto = out.File
+	file_demostats_proto_rawDesc = nil
+	file_demostats_proto_goTypes = nil
+	file_demostats_proto_depIdxs = nil
+}
@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: demostats.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DemoStatsClient is the client API for DemoStats service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DemoStatsClient interface {
+	// Parse parses a demo file uploaded as a stream of chunks
+	Parse(ctx context.Context, opts ...grpc.CallOption) (DemoStats_ParseClient, error)
+	// ParseRemote downloads and parses a demo file from a remote url
+	ParseRemote(ctx context.Context, in *ParseRemoteRequest, opts ...grpc.CallOption) (*Match, error)
+}
+
+type demoStatsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDemoStatsClient(cc grpc.ClientConnInterface) DemoStatsClient {
+	return &demoStatsClient{cc}
+}
+
+func (c *demoStatsClient) Parse(ctx context.Context, opts ...grpc.CallOption) (DemoStats_ParseClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DemoStats_ServiceDesc.Streams[0], "/demostats.DemoStats/Parse", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &demoStatsParseClient{stream}
+	return x, nil
+}
+
+type DemoStats_ParseClient interface {
+	Send(*ParseRequest) error
+	CloseAndRecv() (*Match, error)
+	grpc.ClientStream
+}
+
+type demoStatsParseClient struct {
+	grpc.ClientStream
+}
+
+func (x *demoStatsParseClient) Send(m *ParseRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *demoStatsParseClient) CloseAndRecv() (*Match, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Match)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *demoStatsClient) ParseRemote(ctx context.Context, in *ParseRemoteRequest, opts ...grpc.CallOption) (*Match, error) {
+	out := new(Match)
+	err := c.cc.Invoke(ctx, "/demostats.DemoStats/ParseRemote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DemoStatsServer is the server API for DemoStats service.
+// All implementations must embed UnimplementedDemoStatsServer
+// for forward compatibility
+type DemoStatsServer interface {
+	// Parse parses a demo file uploaded as a stream of chunks
+	Parse(DemoStats_ParseServer) error
+	// ParseRemote downloads and parses a demo file from a remote url
+	ParseRemote(context.Context, *ParseRemoteRequest) (*Match, error)
+	mustEmbedUnimplementedDemoStatsServer()
+}
+
+// UnimplementedDemoStatsServer must be embedded to have forward compatible implementations.
+type UnimplementedDemoStatsServer struct {
+}
+
+func (UnimplementedDemoStatsServer) Parse(DemoStats_ParseServer) error {
+	return status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedDemoStatsServer) ParseRemote(context.Context, *ParseRemoteRequest) (*Match, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ParseRemote not implemented")
+}
+func (UnimplementedDemoStatsServer) mustEmbedUnimplementedDemoStatsServer() {}
+
+// UnsafeDemoStatsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DemoStatsServer will
+// result in compilation errors.
+type UnsafeDemoStatsServer interface {
+	mustEmbedUnimplementedDemoStatsServer()
+}
+
+func RegisterDemoStatsServer(s grpc.ServiceRegistrar, srv DemoStatsServer) {
+	s.RegisterService(&DemoStats_ServiceDesc, srv)
+}
+
+func _DemoStats_Parse_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DemoStatsServer).Parse(&demoStatsParseServer{stream})
+}
+
+type DemoStats_ParseServer interface {
+	SendAndClose(*Match) error
+	Recv() (*ParseRequest, error)
+	grpc.ServerStream
+}
+
+type demoStatsParseServer struct {
+	grpc.ServerStream
+}
+
+func (x *demoStatsParseServer) SendAndClose(m *Match) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *demoStatsParseServer) Recv() (*ParseRequest, error) {
+	m := new(ParseRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DemoStats_ParseRemote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRemoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DemoStatsServer).ParseRemote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/demostats.DemoStats/ParseRemote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DemoStatsServer).ParseRemote(ctx, req.(*ParseRemoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DemoStats_ServiceDesc is the grpc.ServiceDesc for DemoStats service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DemoStats_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "demostats.DemoStats",
+	HandlerType: (*DemoStatsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ParseRemote",
+			Handler:    _DemoStats_ParseRemote_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Parse",
+			Handler:       _DemoStats_Parse_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "demostats.proto",
+}
@@ -0,0 +1,5 @@
+// Package pb contains the protobuf messages and gRPC service definitions of
+// the demo stats service
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative demostats.proto
@@ -0,0 +1,207 @@
+package demostats
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Broadcast is a GOTV http broadcast of a running match, e.g. served by a
+// broadcast relay
+type Broadcast struct {
+	// URL of the match, the fragments are requested below it, e.g.
+	// "http://relay:8080/match/s85568392920768736t1477086968"
+	URL string
+
+	// Client sends the requests, defaults to http.DefaultClient
+	Client *http.Client
+
+	// PollInterval is the wait before a fragment that is not available yet
+	// is requested again, defaults to one second
+	PollInterval time.Duration
+
+	// Timeout is the time without a new fragment after which the broadcast
+	// is considered ended, defaults to one minute
+	Timeout time.Duration
+}
+
+// broadcastSync is the response of the sync request of a broadcast
+type broadcastSync struct {
+	Fragment       int    `json:"fragment"`
+	SignupFragment int    `json:"signup_fragment"`
+	Map            string `json:"map"`
+	Protocol       int    `json:"protocol"`
+}
+
+// ParseBroadcast parses a GOTV broadcast like Parse until the broadcast ends
+// or ctx is done. The broadcast is joined at its latest full fragment, so
+// the stats only cover the match from there on. Use Options.RoundEnd and
+// Options.Progress to follow the match while it is played.
+//
+// A broadcast that stops without the end of the demo returns the rounds up
+// to that point like a truncated demo. ErrBroadcastUnavailable is returned
+// if the broadcast can't be joined
+func ParseBroadcast(ctx context.Context, b Broadcast, opts Options) (*Match, error) {
+	if b.Client == nil {
+		b.Client = http.DefaultClient
+	}
+	if b.PollInterval <= 0 {
+		b.PollInterval = time.Second
+	}
+	if b.Timeout <= 0 {
+		b.Timeout = time.Minute
+	}
+	b.URL = strings.TrimSuffix(b.URL, "/")
+
+	// Options.MaxDuration also ends the wait for the next fragment
+	if opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxDuration)
+		defer cancel()
+	}
+
+	r, err := openBroadcast(ctx, b)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded && opts.MaxDuration > 0 {
+			return nil, ErrParseTimeout
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return Parse(ctx, r, opts)
+}
+
+// broadcastReader reads the fragments of a broadcast as one demo stream
+type broadcastReader struct {
+	ctx  context.Context
+	b    Broadcast
+	buf  bytes.Reader
+	next int
+}
+
+// openBroadcast requests the sync, start and full fragment of the broadcast
+// and returns the reader of the demo stream starting with them
+func openBroadcast(ctx context.Context, b Broadcast) (*broadcastReader, error) {
+	br := &broadcastReader{ctx: ctx, b: b}
+
+	body, status, err := br.get("sync")
+	if err != nil || status != http.StatusOK {
+		return nil, fmt.Errorf("%w: sync returned %d %v", ErrBroadcastUnavailable, status, err)
+	}
+	var sync broadcastSync
+	if err := json.Unmarshal(body, &sync); err != nil {
+		return nil, fmt.Errorf("%w: invalid sync response: %v", ErrBroadcastUnavailable, err)
+	}
+	if sync.Protocol == 0 {
+		sync.Protocol = csgoDemoProtocol
+	}
+
+	stream := broadcastHeader(sync)
+	for _, path := range []string{
+		fmt.Sprintf("%d/start", sync.SignupFragment),
+		fmt.Sprintf("%d/full", sync.Fragment),
+	} {
+		body, status, err := br.get(path)
+		if err != nil || status != http.StatusOK {
+			return nil, fmt.Errorf("%w: %s returned %d %v", ErrBroadcastUnavailable, path, status, err)
+		}
+		stream = append(stream, body...)
+	}
+
+	br.buf.Reset(stream)
+	br.next = sync.Fragment
+	return br, nil
+}
+
+// Read reads the demo stream, waiting for the next delta fragment once all
+// received fragments are read. Returns io.EOF once no new fragment was
+// published for the timeout of the broadcast
+func (br *broadcastReader) Read(b []byte) (int, error) {
+	if br.buf.Len() == 0 {
+		if err := br.fetchNext(); err != nil {
+			return 0, err
+		}
+	}
+	return br.buf.Read(b)
+}
+
+// fetchNext waits for the next delta fragment and buffers it
+func (br *broadcastReader) fetchNext() error {
+	deadline := time.Now().Add(br.b.Timeout)
+	path := fmt.Sprintf("%d/delta", br.next)
+	for {
+		body, status, err := br.get(path)
+		if err == nil && status == http.StatusOK && len(body) > 0 {
+			br.buf.Reset(body)
+			br.next++
+			return nil
+		}
+		if ctxErr := br.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if time.Now().After(deadline) {
+			// The broadcast is over
+			return io.EOF
+		}
+
+		select {
+		case <-br.ctx.Done():
+			return br.ctx.Err()
+		case <-time.After(br.b.PollInterval):
+		}
+	}
+}
+
+// get requests a path below the broadcast url
+func (br *broadcastReader) get(path string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(br.ctx, "GET", br.b.URL+"/"+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := br.b.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
+
+// broadcastHeader returns a demo header for the broadcast, the fragments
+// only contain the demo commands
+func broadcastHeader(sync broadcastSync) []byte {
+	const maxOsPath = 260
+	h := make([]byte, 0, 1072)
+	h = append(h, "HL2DEMO\x00"...)
+	h = appendInt32(h, sync.Protocol)
+	h = appendInt32(h, 0)
+	h = appendCString(h, "GOTV broadcast", maxOsPath)
+	h = appendCString(h, "GOTV Demo", maxOsPath)
+	h = appendCString(h, sync.Map, maxOsPath)
+	h = appendCString(h, "csgo", maxOsPath)
+	// Playback time, ticks, frames and signon length are unknown
+	return append(h, make([]byte, 16)...)
+}
+
+func appendInt32(b []byte, v int) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(int32(v)))
+	return append(b, buf[:]...)
+}
+
+// appendCString appends s as a zero padded string of size bytes
+func appendCString(b []byte, s string, size int) []byte {
+	field := make([]byte, size)
+	copy(field[:size-1], s)
+	return append(b, field...)
+}
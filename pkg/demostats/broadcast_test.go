@@ -0,0 +1,108 @@
+package demostats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// demoCommand returns a demo command without payload
+func demoCommand(cmd byte, tick byte) []byte {
+	return []byte{cmd, tick, 0, 0, 0, 0}
+}
+
+// broadcastRelay is a broadcast relay stub serving recorded fragments.
+// Delta fragments are published one per request of the previous one, like
+// a match that is still running
+type broadcastRelay struct {
+	mu        sync.Mutex
+	fragments map[string][]byte
+	pending   [][]byte
+	requests  []string
+}
+
+func (br *broadcastRelay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.requests = append(br.requests, r.URL.Path)
+
+	b, ok := br.fragments[r.URL.Path]
+	if !ok {
+		// Not available yet, publish the next fragment
+		if len(br.pending) > 0 {
+			br.fragments[r.URL.Path] = br.pending[0]
+			br.pending = br.pending[1:]
+		}
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(b)
+}
+
+func TestParseBroadcast(t *testing.T) {
+	const synctick, stop = 3, 7
+	relay := &broadcastRelay{
+		fragments: map[string][]byte{
+			"/match/sync":    []byte(`{"tick":1000,"fragment":5,"signup_fragment":1,"tps":128,"map":"de_dust2","protocol":4}`),
+			"/match/1/start": demoCommand(synctick, 1),
+			"/match/5/full":  demoCommand(synctick, 2),
+			"/match/5/delta": demoCommand(synctick, 3),
+		},
+		pending: [][]byte{demoCommand(stop, 4)},
+	}
+	srv := httptest.NewServer(relay)
+	defer srv.Close()
+
+	// The parser reads ahead, the last command is parsed once the broadcast
+	// timed out
+	b := Broadcast{URL: srv.URL + "/match/", PollInterval: time.Millisecond, Timeout: time.Millisecond * 50}
+	m, err := ParseBroadcast(context.Background(), b, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "de_dust2", m.General.MapName)
+	assert.Equal(t, []string{
+		"/match/sync", "/match/1/start", "/match/5/full", "/match/5/delta",
+		"/match/6/delta", "/match/6/delta", "/match/7/delta",
+	}, relay.requests[:7])
+}
+
+func TestParseBroadcastEnded(t *testing.T) {
+	relay := &broadcastRelay{fragments: map[string][]byte{
+		"/match/sync":    []byte(`{"fragment":5,"signup_fragment":1,"map":"de_dust2"}`),
+		"/match/1/start": demoCommand(3, 1),
+		"/match/5/full":  demoCommand(3, 2),
+	}}
+	srv := httptest.NewServer(relay)
+	defer srv.Close()
+
+	// No delta fragment is published before the timeout
+	b := Broadcast{URL: srv.URL + "/match", PollInterval: time.Millisecond, Timeout: time.Millisecond * 50}
+	_, err := ParseBroadcast(context.Background(), b, Options{})
+	assert.Equal(t, ErrTruncatedDemo, err)
+
+	b.URL = srv.URL + "/missing"
+	_, err = ParseBroadcast(context.Background(), b, Options{})
+	assert.True(t, errors.Is(err, ErrBroadcastUnavailable), "got %v", err)
+}
+
+func TestParseBroadcastMaxDuration(t *testing.T) {
+	relay := &broadcastRelay{fragments: map[string][]byte{
+		"/match/sync":    []byte(`{"fragment":5,"signup_fragment":1,"map":"de_dust2"}`),
+		"/match/1/start": demoCommand(3, 1),
+		"/match/5/full":  demoCommand(3, 2),
+	}}
+	srv := httptest.NewServer(relay)
+	defer srv.Close()
+
+	// The wait for the next fragment ends with the parse
+	b := Broadcast{URL: srv.URL + "/match", PollInterval: time.Millisecond, Timeout: time.Minute}
+	start := time.Now()
+	_, err := ParseBroadcast(context.Background(), b, Options{MaxDuration: time.Millisecond * 50})
+	assert.Equal(t, ErrParseTimeout, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second*5))
+}
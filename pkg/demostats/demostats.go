@@ -0,0 +1,196 @@
+// Package demostats parses CSGO demo files into match statistics.
+//
+// The result of a parse is a Match. Its JSON representation is the stable
+// result schema of this package: fields may be added, but renaming or
+// removing fields or changing their meaning increases SchemaVersion.
+package demostats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SchemaVersion is the version of the result schema, it is set on every
+// parsed Match.
+//
+// Version 2 averages adr, rating, rws and efpr over the rounds played by
+// each player instead of the rounds of the match.
+//
+// Version 3 gives every bot its own entry: steamid is "BOT", steamid64 is a
+// synthetic id derived from the bot's name and the name is prefixed with
+// "BOT ". Kills, damage and shots of a bot taken over by a player are
+// credited to the player.
+//
+// Version 4 leaves knife rounds out of all stats
+const SchemaVersion = 4
+
+var (
+	// ErrInvalidFile signals that the input isn't a CSGO demo file
+	ErrInvalidFile = errors.New("input is not a valid CSGO demo file (ErrInvalidFile)")
+
+	// ErrTruncatedDemo signals that the demo ended unexpectedly, e.g. because
+	// the server crashed while recording
+	ErrTruncatedDemo = errors.New("demo file ended unexpectedly (ErrTruncatedDemo)")
+
+	// ErrUnsupportedDemoVersion signals a demo of an unsupported game or demo
+	// protocol version, e.g. a CS2 demo
+	ErrUnsupportedDemoVersion = errors.New("demo version is not supported (ErrUnsupportedDemoVersion)")
+
+	// ErrInternal signals a failure inside the parser. Errors of this kind
+	// wrap ErrInternal and carry the original cause in their message
+	ErrInternal = errors.New("internal parser error (ErrInternal)")
+
+	// ErrParseTimeout signals that parsing took longer than Options.MaxDuration
+	ErrParseTimeout = errors.New("parsing took longer than the maximum parse duration (ErrParseTimeout)")
+
+	// ErrDemoTooLarge signals that the demo is larger than Options.MaxSize
+	ErrDemoTooLarge = errors.New("demo file exceeds the maximum demo size (ErrDemoTooLarge)")
+
+	// ErrBroadcastUnavailable signals that a GOTV broadcast could not be
+	// joined, e.g. because it doesn't exist (yet)
+	ErrBroadcastUnavailable = errors.New("GOTV broadcast is not available (ErrBroadcastUnavailable)")
+)
+
+// Match is the result of a parse
+type Match = InfoStruct
+
+// Options configures a parse
+type Options struct {
+	// MatchID is set as the MatchID of the result
+	MatchID string
+
+	// MaxDuration limits how long parsing may take, zero means no limit
+	MaxDuration time.Duration
+
+	// MaxSize limits the size of the demo file in bytes, zero means no limit
+	MaxSize int64
+
+	// Logger receives the logs of the parse, e.g. scoped to a request.
+	// Defaults to the standard logrus logger
+	Logger *log.Entry
+
+	// Progress is called while parsing whenever the parsed percent, the
+	// round or the score changes. It runs on the parsing goroutine and
+	// should return quickly
+	Progress func(Progress)
+
+	// RoundEnd is called after every round with a copy of the match parsed
+	// so far, its stats calculated over the rounds up to this one. It runs
+	// on the parsing goroutine
+	RoundEnd func(*Match)
+
+	// Chat records the chat messages of the match in Match.Chat
+	Chat bool
+
+	// ChatWords only keeps the chat messages containing one of the words or
+	// phrases like "gg wp", matched as whole words ignoring case and
+	// punctuation. Empty keeps all messages
+	ChatWords []string
+
+	// MatchTime is set as the time of the match. ParseFile takes it from
+	// the file name or modification time without it, Parse uses the time
+	// of the parse
+	MatchTime time.Time
+}
+
+// Progress is the state of a running parse
+type Progress struct {
+	// Percent of the demo frames parsed, from 0 to 100
+	Percent int `json:"percent"`
+	Round   int `json:"round"`
+	ScoreA  int `json:"score_a"`
+	ScoreB  int `json:"score_b"`
+}
+
+// Parse parses a demo file read from r and returns the statistics of the
+// match. Parsing stops when ctx is done.
+//
+// Failed parses return one of the errors declared in this package, use
+// errors.Is to check for them
+func Parse(ctx context.Context, r io.Reader, opts Options) (*Match, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxDuration)
+		defer cancel()
+	}
+
+	lr := &limitReader{r: r, remaining: opts.MaxSize}
+	if opts.MaxSize > 0 {
+		r = lr
+	}
+
+	p := NewDemoParser()
+	if opts.Logger != nil {
+		p.Logger = opts.Logger
+	}
+	p.OnProgress = opts.Progress
+	p.OnRoundEnd = opts.RoundEnd
+	p.CaptureChat = opts.Chat
+	p.ChatWords = opts.ChatWords
+	p.MatchTime = opts.MatchTime
+	m := &Match{MatchID: opts.MatchID}
+	err := p.Parse(ctx, r, m)
+
+	if lr.exceeded {
+		return m, ErrDemoTooLarge
+	}
+	if err == context.DeadlineExceeded && opts.MaxDuration > 0 {
+		return m, ErrParseTimeout
+	}
+	return m, err
+}
+
+// ParseFile parses the demo file at path like Parse. Without
+// Options.MatchID the match id is taken from the file name like
+// DemoParser.ParseFromDisk does
+func ParseFile(ctx context.Context, path string, opts Options) (*Match, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if opts.MatchID == "" {
+		opts.MatchID = MatchIDFromPath(path)
+	}
+	if info, err := f.Stat(); err == nil && opts.MatchTime.IsZero() {
+		opts.MatchTime = matchTimeOfFile(path, info)
+	}
+	return Parse(ctx, f, opts)
+}
+
+// limitReader reads at most remaining bytes and records whether the
+// underlying reader had more data
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (lr *limitReader) Read(b []byte) (int, error) {
+	if lr.remaining <= 0 {
+		// Probe for more data to tell a demo of exactly the maximum size
+		// apart from a larger one
+		n, err := lr.r.Read(make([]byte, 1))
+		if n > 0 {
+			lr.exceeded = true
+			return 0, io.EOF
+		}
+		return 0, err
+	}
+	if int64(len(b)) > lr.remaining {
+		b = b[:lr.remaining]
+	}
+	n, err := lr.r.Read(b)
+	lr.remaining -= int64(n)
+	return n, err
+}
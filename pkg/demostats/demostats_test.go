@@ -0,0 +1,597 @@
+package demostats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Parse(ctx, bytes.NewReader(nil), Options{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+// demoHeader returns a demo header with the given filestamp and protocol
+func demoHeader(filestamp string, protocol byte) []byte {
+	b := make([]byte, 1072)
+	copy(b, filestamp)
+	b[8] = protocol
+	return b
+}
+
+// newTestParser returns the parser of a started match with the players on
+// the scoreboard, team A playing CT. The rounds are played with fire
+func newTestParser(players ...*common.Player) *DemoParser {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{MatchValid: true}
+	p.Match.RdDamages.RdDamages = NewRdDamages()
+	p.state.MatchStarted = true
+	p.state.TeamA = common.TeamCounterTerrorists
+	for _, pl := range players {
+		// NewScoreBoardPlayer needs the entities of a demo
+		name := pl.Name
+		if pl.IsBot {
+			name = botName(pl)
+		}
+		team := "B"
+		if pl.Team == p.state.TeamA {
+			team = "A"
+		}
+		p.Match.Players.Players = append(p.Match.Players.Players, ScoreboardPlayer{
+			IsBot:         pl.IsBot,
+			IsAMember:     team == "A",
+			TeamChar:      team,
+			Name:          name,
+			Steamid64:     playerID(pl),
+			WeaponStats:   NewWeaponstats(),
+			PlayerDamages: NewPlayerDamages(),
+		})
+	}
+	return &p
+}
+
+// fire passes the events to the handlers of the parser in order
+func fire(p *DemoParser, evs ...interface{}) {
+	for _, e := range evs {
+		switch e := e.(type) {
+		case events.RoundStart:
+			p.handlerRoundStart(e)
+		case events.RoundEnd:
+			p.handlerRoundEnd(e)
+		case events.WeaponFire:
+			p.handlerWeaponFire(e)
+		case events.PlayerHurt:
+			p.handlerPlayerHurt(e)
+		case events.Kill:
+			p.handlerKill(e)
+		case events.BombDefused:
+			p.handlerBombDefused(e)
+		case events.MatchStart:
+			p.handlerMatchStart(e)
+		default:
+			panic(fmt.Sprintf("no handler for %T", e))
+		}
+	}
+}
+
+// testPlayers returns a CT and a T player with the ids 1 and 2
+func testPlayers() (a, b *common.Player) {
+	return &common.Player{SteamID64: 1, Name: "a", Team: common.TeamCounterTerrorists},
+		&common.Player{SteamID64: 2, Name: "b", Team: common.TeamTerrorists}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []byte
+		err   error
+	}{
+		{"shorter than header", []byte("HL2DEMO"), ErrTruncatedDemo},
+		{"no demo", bytes.Repeat([]byte("not a demo"), 200), ErrInvalidFile},
+		{"cs2 demo", demoHeader("PBDEMS2", 0), ErrUnsupportedDemoVersion},
+		{"old protocol", demoHeader("HL2DEMO", 3), ErrUnsupportedDemoVersion},
+		{"header only", demoHeader("HL2DEMO", 4), ErrTruncatedDemo},
+	}
+
+	for _, c := range cases {
+		_, err := Parse(context.Background(), bytes.NewReader(c.input), Options{})
+		assert.True(t, errors.Is(err, c.err), "%s: got %v", c.name, err)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "demostats")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "1234_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(path, demoHeader("HL2DEMO", 4), 0644))
+
+	m, err := ParseFile(context.Background(), path, Options{})
+	assert.True(t, errors.Is(err, ErrTruncatedDemo), "got %v", err)
+	assert.Equal(t, "1234", m.MatchID)
+
+	m, _ = ParseFile(context.Background(), path, Options{MatchID: "5678"})
+	assert.Equal(t, "5678", m.MatchID)
+}
+
+func TestMatchTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "demostats")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Without a date in the file name the modification time is used
+	path := filepath.Join(dir, "1234_de_dust2.dem")
+	assert.NoError(t, ioutil.WriteFile(path, demoHeader("HL2DEMO", 4), 0644))
+	mtime := time.Date(2021, 6, 6, 18, 30, 0, 0, time.Local)
+	assert.NoError(t, os.Chtimes(path, mtime, mtime))
+	m, _ := ParseFile(context.Background(), path, Options{})
+	assert.True(t, mtime.Equal(m.General.MatchTime), "got %v", m.General.MatchTime)
+
+	for name, want := range map[string]time.Time{
+		"2021-6-6_pug_de_overpass_60bc5197.dem":           time.Date(2021, 6, 6, 0, 0, 0, 0, time.Local),
+		"pug_de_mirage_2021-06-07_21-05.dem":              time.Date(2021, 6, 7, 21, 5, 0, 0, time.Local),
+		"auto0-20210608-183012-1234567-de_dust2-GOTV.dem": time.Date(2021, 6, 8, 18, 30, 12, 0, time.Local),
+	} {
+		got, ok := matchTimeFromPath(filepath.Join(dir, name))
+		assert.True(t, ok, name)
+		assert.True(t, want.Equal(got), "%s: got %v", name, got)
+	}
+	_, ok := matchTimeFromPath("2021-13-40_de_dust2.dem")
+	assert.False(t, ok)
+	_, ok = matchTimeFromPath("1234_de_dust2.dem")
+	assert.False(t, ok)
+}
+
+func TestSetGeneralHeader(t *testing.T) {
+	const maxOsPath = 260
+	h := append([]byte("HL2DEMO\x00"), 4, 0, 0, 0, 0, 0, 0, 0)
+	h = appendCString(h, "My Server", maxOsPath)
+	h = appendCString(h, "GOTV Demo", maxOsPath)
+	h = appendCString(h, "de_dust2", maxOsPath)
+	h = appendCString(h, "csgo", maxOsPath)
+	// 60 seconds of 128 tick, recorded at 32 frames per second
+	h = append(h, 0, 0, 0x70, 0x42)
+	h = appendInt32(h, 7680)
+	h = appendInt32(h, 1920)
+	h = appendInt32(h, 0)
+
+	matchTime := time.Date(2021, 6, 6, 0, 0, 0, 0, time.UTC)
+	m, err := Parse(context.Background(), bytes.NewReader(h), Options{MatchTime: matchTime})
+	assert.True(t, errors.Is(err, ErrTruncatedDemo), "got %v", err)
+	assert.InDelta(t, time.Minute, m.General.DemoDuration, float64(time.Millisecond))
+	m.General.DemoDuration = time.Minute
+	assert.Equal(t, ScoreboardGeneral{
+		MapName:        "de_dust2",
+		MapIconURL:     "de_dust2",
+		MatchTime:      matchTime,
+		ServerName:     "My Server",
+		ClientName:     "GOTV Demo",
+		DemoType:       DemoTypeGOTV,
+		TickRate:       128,
+		PlaybackTicks:  7680,
+		PlaybackFrames: 1920,
+		DemoDuration:   time.Minute,
+	}, m.General)
+
+	// POV demos are named after the player recording them
+	assert.Equal(t, DemoTypePOV, demoType(common.DemoHeader{ClientName: "player"}))
+}
+
+func TestSetDurations(t *testing.T) {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{}
+	p.state.MatchStartTime = time.Minute
+	p.state.LastRoundEnd = time.Minute * 41
+
+	p.setDurations()
+	assert.Equal(t, time.Minute*40, p.Match.General.MatchDuration)
+}
+
+func TestLimitReader(t *testing.T) {
+	// Exactly the maximum size is fine
+	lr := &limitReader{r: bytes.NewReader(make([]byte, 10)), remaining: 10}
+	b, err := ioutil.ReadAll(lr)
+	assert.NoError(t, err)
+	assert.Len(t, b, 10)
+	assert.False(t, lr.exceeded)
+
+	// One byte more is not
+	lr = &limitReader{r: bytes.NewReader(make([]byte, 11)), remaining: 10}
+	b, err = ioutil.ReadAll(lr)
+	assert.NoError(t, err)
+	assert.Len(t, b, 10)
+	assert.True(t, lr.exceeded)
+
+	_, err = lr.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMarkPartial(t *testing.T) {
+	p := NewDemoParser()
+	p.Match = &Match{MatchValid: true, Rounds: make([]ScoreboardRound, 5)}
+	p.state.Round = 5
+	p.state.RoundOngoing = true
+	p.state.RoundsEnded = 3
+
+	p.markPartial()
+	assert.False(t, p.Match.MatchValid)
+	assert.True(t, p.Match.Rounds[4].Incomplete)
+	assert.Len(t, p.Match.Errors, 1)
+}
+
+func TestIncompleteRoundDropped(t *testing.T) {
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+	ak := common.NewEquipment(common.EqAK47)
+	fire(p, events.RoundStart{}, events.WeaponFire{Shooter: a, Weapon: ak},
+		events.Kill{Killer: a, Victim: b, Weapon: ak}, events.RoundEnd{Winner: common.TeamCounterTerrorists})
+	fire(p, events.RoundStart{}, events.PlayerHurt{Attacker: a, Player: b, Weapon: ak},
+		events.Kill{Killer: a, Victim: b, Weapon: ak})
+	p.Match.Players.Players = append(p.Match.Players.Players, ScoreboardPlayer{Steamid64: 3, Substitute: true})
+	assert.Equal(t, 2, p.Match.Players.Players[0].Firstkills)
+
+	// The stats of the cut off round are dropped along with the players
+	// that joined during it
+	p.markPartial()
+	assert.True(t, p.Match.Rounds[1].Incomplete)
+	assert.Len(t, p.Match.Players.Players, 2)
+	assert.Equal(t, 1, p.Match.Players.Players[0].Firstkills)
+	assert.Equal(t, map[common.EquipmentType]int{common.EqAK47: 1}, p.Match.Players.Players[0].WeaponStats.Kills)
+	assert.Empty(t, p.Match.Players.Players[0].WeaponStats.Hits)
+	assert.Equal(t, playerScore{}, p.score(a))
+	// The kills of the round are still listed
+	assert.Len(t, p.Match.Rounds[1].AKills, 1)
+}
+
+func TestCalculateOwnRounds(t *testing.T) {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{Rounds: make([]ScoreboardRound, 21)}
+	p.Match.Players.Players = []ScoreboardPlayer{
+		{Steamid64: 1, TeamChar: "A", RoundsPlayed: 20, PlayerDamages: PlayerDamages{Damages: map[uint64]int{2: 1000, 3: 1000}}},
+		{Steamid64: 2, TeamChar: "B", RoundsPlayed: 12, PlayerDamages: PlayerDamages{Damages: map[uint64]int{1: 600}}},
+		{Steamid64: 3, TeamChar: "B", RoundsPlayed: 8, Substitute: true, PlayerDamages: PlayerDamages{Damages: map[uint64]int{1: 800}}},
+		{Steamid64: 4, TeamChar: "B"},
+	}
+	p.calculate()
+
+	assert.Equal(t, 100.0, p.Match.Players.Players[0].Adr)
+	assert.Equal(t, 50.0, p.Match.Players.Players[1].Adr)
+	assert.Equal(t, 100.0, p.Match.Players.Players[2].Adr)
+	// A player without rounds has no per-round stats
+	assert.Equal(t, 0.0, p.Match.Players.Players[3].Adr)
+	assert.Equal(t, 0.0, p.Match.Players.Players[3].Rating)
+}
+
+func TestAddConnection(t *testing.T) {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{}
+	player := &common.Player{SteamID64: 1, Name: "sub"}
+	bot := &common.Player{Name: "Bot", IsBot: true}
+
+	// Connections before the match started are the lineup joining
+	p.handlerPlayerConnect(events.PlayerConnect{Player: player})
+	assert.Empty(t, p.Match.Connections)
+
+	p.state.MatchStarted = true
+	p.state.Round = 7
+	p.handlerPlayerDisconnected(events.PlayerDisconnected{Player: player})
+	p.handlerPlayerConnect(events.PlayerConnect{Player: bot})
+	p.handlerPlayerConnect(events.PlayerConnect{Player: player})
+	assert.Equal(t, []Connection{
+		{Round: 7, Steamid64: 1, Name: "sub"},
+		{Round: 7, Steamid64: 1, Name: "sub", Connected: true},
+	}, p.Match.Connections)
+}
+
+func TestParserLogFields(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	p := NewDemoParser()
+	p.Logger = logrus.NewEntry(logger).WithField("request_id", "abc")
+	p.Match = &Match{MatchID: "1234", General: ScoreboardGeneral{MapName: "de_dust2"}}
+	p.state.Round = 3
+
+	p.warn("Created new player for ID ", 5)
+	entry := hook.LastEntry()
+	assert.Equal(t, "Created new player for ID 5", entry.Message)
+	assert.Equal(t, "abc", entry.Data["request_id"])
+	assert.Equal(t, "1234", entry.Data["match_id"])
+	assert.Equal(t, "de_dust2", entry.Data["map"])
+	assert.Equal(t, 3, entry.Data["round"])
+	assert.Equal(t, []string{"round 3: Created new player for ID 5"}, p.Match.Warnings)
+}
+
+func TestParserProgress(t *testing.T) {
+	var reported []Progress
+	p := NewDemoParser()
+	p.OnProgress = func(progress Progress) { reported = append(reported, progress) }
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{}
+
+	p.state.Round = 1
+	p.reportProgress()
+	p.reportProgress()
+	p.Match.General.ScoreA = 1
+	p.reportProgress()
+
+	assert.Equal(t, []Progress{{Round: 1}, {Round: 1, ScoreA: 1}}, reported)
+}
+
+func TestParserSnapshot(t *testing.T) {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{Rounds: make([]ScoreboardRound, 3)}
+	p.Match.Players.Players = []ScoreboardPlayer{
+		{Steamid64: 1, TeamChar: "A", RoundsPlayed: 2, PlayerDamages: PlayerDamages{Damages: map[uint64]int{2: 200}}},
+		{Steamid64: 2, TeamChar: "B"},
+	}
+
+	// The stats are calculated on the copy only
+	m, err := p.snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, m.Players.Players[0].Adr)
+	assert.Equal(t, 0.0, p.Match.Players.Players[0].Adr)
+	assert.Empty(t, p.Match.Errors)
+}
+
+func TestHandlerChatMessage(t *testing.T) {
+	p := NewDemoParser()
+	p.parser = demoinfocs.NewParser(bytes.NewReader(demoHeader("HL2DEMO", csgoDemoProtocol)))
+	p.Match = &Match{}
+	p.state.Round = 4
+	p.state.TeamA = common.TeamTerrorists
+
+	ct := &common.Player{SteamID64: 1, Name: "ct", Team: common.TeamCounterTerrorists}
+	spec := &common.Player{SteamID64: 2, Name: "spec", Team: common.TeamSpectators}
+	p.handlerChatMessage(events.ChatMessage{Sender: ct, Text: "gg", IsChatAll: true})
+	p.handlerChatMessage(events.ChatMessage{Sender: spec, Text: "hi"})
+	assert.Equal(t, []ChatMessage{
+		{Round: 4, Steamid64: 1, Name: "ct", TeamChar: "B", AllChat: true, Text: "gg"},
+		{Round: 4, Steamid64: 2, Name: "spec", Text: "hi"},
+	}, p.Match.Chat)
+
+	// Only messages with a word of the list are kept
+	p.Match.Chat = nil
+	p.ChatWords = []string{"Noob", "trash"}
+	p.handlerChatMessage(events.ChatMessage{Sender: ct, Text: "you NOOB, trash!"})
+	p.handlerChatMessage(events.ChatMessage{Sender: ct, Text: "noobish"})
+	assert.Len(t, p.Match.Chat, 1)
+	assert.Equal(t, []string{"Noob", "trash"}, p.Match.Chat[0].Matches)
+}
+
+func TestMatchWords(t *testing.T) {
+	words := []string{"gg wp", "ez", "no  SKILL", "!!"}
+	assert.Equal(t, []string{"gg wp", "ez"}, matchWords("GG, wp! ez", words))
+	assert.Equal(t, []string{"no  SKILL"}, matchWords("no-skill team", words))
+	assert.Empty(t, matchWords("ggwp eze", words))
+	assert.Empty(t, matchWords("gg", words))
+	assert.Empty(t, matchWords("!!", words))
+
+	for _, msg := range []string{"noob!", "(noob)", "noob.", "gg,noob", "'noob'?"} {
+		assert.Equal(t, []string{"noob"}, matchWords(msg, []string{"noob"}), msg)
+	}
+	assert.Empty(t, matchWords("noobs!", []string{"noob"}))
+}
+
+func TestBotTakeover(t *testing.T) {
+	human := &common.Player{SteamID64: 1, Name: "human", Team: common.TeamCounterTerrorists}
+	bot := &common.Player{Name: "Albert", IsBot: true, Team: common.TeamCounterTerrorists}
+	other := &common.Player{Name: "Bert", IsBot: true, Team: common.TeamTerrorists}
+	p := newTestParser(human, bot, other)
+	assert.Equal(t, "BOT Albert", p.Match.Players.Players[1].Name)
+	assert.NotEqual(t, playerID(bot), playerID(other))
+
+	// The kill of the bot is credited to the player controlling it.
+	// handlerBotTakenOver needs the entities of a demo
+	weapon := common.NewEquipment(common.EqAK47)
+	fire(p, events.RoundStart{})
+	p.state.BotControllers = map[uint64]*common.Player{playerID(bot): human}
+	fire(p, events.Kill{Killer: bot, Victim: other, Weapon: weapon})
+	kill := p.Match.Rounds[0].AKills[0]
+	assert.Equal(t, uint64(1), kill.Killer.Steamid64)
+	assert.True(t, kill.BotControlled)
+	assert.Equal(t, 1, p.Match.Players.Players[0].WeaponStats.Kills[common.EqAK47])
+	assert.Empty(t, p.Match.Players.Players[1].WeaponStats.Kills)
+
+	// So is the death of a bot taken over
+	fire(p, events.Kill{Killer: other, Victim: bot, Weapon: weapon})
+	kill = p.Match.Rounds[0].BKills[0]
+	assert.Equal(t, uint64(1), kill.Victim.Steamid64)
+	assert.Equal(t, 1, p.Match.Players.Players[0].Firstdeaths)
+	assert.Equal(t, 0, p.Match.Players.Players[1].Firstdeaths)
+}
+
+func TestKillWithoutWeapon(t *testing.T) {
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+
+	fire(p, events.RoundStart{}, events.Kill{Killer: a, Victim: b, IsHeadshot: true})
+	assert.Equal(t, common.EqUnknown, p.Match.Rounds[0].AKills[0].KillerWeapon)
+	assert.Equal(t, 1, p.Match.Players.Players[0].WeaponStats.Kills[common.EqUnknown])
+	assert.Equal(t, 1, p.Match.Players.Players[0].WeaponStats.Headshots[common.EqUnknown])
+	assert.Equal(t, []string{"round 1: kill of b without a weapon, counted as unknown"}, p.Match.Warnings)
+}
+
+func TestRoundPeriod(t *testing.T) {
+	p := newTestParser()
+
+	for n, want := range map[int][2]int{
+		0: {0, 0}, 1: {1, 0}, 15: {1, 0}, 16: {2, 0}, 30: {2, 0},
+		31: {1, 1}, 33: {1, 1}, 34: {2, 1}, 36: {2, 1}, 37: {1, 2}, 42: {2, 2},
+	} {
+		half, overtime := p.roundPeriod(n)
+		assert.Equal(t, want, [2]int{half, overtime}, "round %d", n)
+	}
+
+	// Wingman matches have 16 rounds
+	p.Match.General.GameMode = ModeWingman
+	for n, want := range map[int][2]int{8: {1, 0}, 9: {2, 0}, 16: {2, 0}, 17: {1, 1}} {
+		half, overtime := p.roundPeriod(n)
+		assert.Equal(t, want, [2]int{half, overtime}, "round %d", n)
+	}
+}
+
+func TestSetGameMode(t *testing.T) {
+	p := newTestParser()
+
+	p.setGameMode(10)
+	assert.Equal(t, ModeCompetitive, p.Match.General.GameMode)
+	p.setGameMode(4)
+	assert.Equal(t, ModeWingman, p.Match.General.GameMode)
+
+	mode, ok := gameModeFromConVars(map[string]string{"game_type": "0", "game_mode": "0"})
+	assert.True(t, ok)
+	assert.Equal(t, ModeCasual, mode)
+	_, ok = gameModeFromConVars(map[string]string{})
+	assert.False(t, ok)
+	assert.Equal(t, competitiveSettings, GameMode("").settings())
+}
+
+func TestRwsByGameMode(t *testing.T) {
+	for mode, want := range map[GameMode]float64{ModeCompetitive: 30, ModeWingman: 12} {
+		a, _ := testPlayers()
+		p := newTestParser(a)
+		p.Match.General.GameMode = mode
+
+		// The defuser gets the same share of the round in every mode
+		fire(p, events.RoundStart{}, events.WeaponFire{Shooter: a, Weapon: common.NewEquipment(common.EqM4A4)},
+			events.BombDefused{BombEvent: events.BombEvent{Player: a}},
+			events.RoundEnd{Winner: common.TeamCounterTerrorists, Reason: events.RoundEndReasonBombDefused})
+		assert.Equal(t, want, p.Match.Players.Players[0].Rws, mode)
+	}
+}
+
+func TestMultiKillsByGameMode(t *testing.T) {
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+	p.Match.General.GameMode = ModeWingman
+	ak := common.NewEquipment(common.EqAK47)
+	kill := events.Kill{Killer: a, Victim: b, Weapon: ak}
+	fire(p, events.RoundStart{}, events.WeaponFire{Shooter: a, Weapon: ak}, kill, kill, kill,
+		events.RoundEnd{Winner: common.TeamCounterTerrorists})
+
+	// More kills than opponents in wingman count as 2K
+	p.calculate()
+	assert.Equal(t, 1, p.Match.Players.Players[0].Rounds2K)
+	assert.Equal(t, 0, p.Match.Players.Players[0].Rounds3K)
+	assert.Contains(t, p.Match.Warnings, "round 1: rating of wingman matches uses the competitive baselines")
+}
+
+func TestAddClutch(t *testing.T) {
+	var sp ScoreboardPlayer
+	for _, opponents := range []int{7, 5, 4, 3, 2, 2} {
+		sp.addClutch(opponents)
+	}
+	assert.Equal(t, []int{2, 1, 1, 2}, []int{sp.Roundswonv5, sp.Roundswonv4, sp.Roundswonv3, sp.Roundswonv2})
+}
+
+func TestKnifeRound(t *testing.T) {
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+	knife := common.NewEquipment(common.EqKnife)
+	fire(p, events.RoundStart{}, events.WeaponFire{Shooter: a, Weapon: knife},
+		events.Kill{Killer: a, Victim: b, Weapon: knife}, events.RoundEnd{Winner: common.TeamCounterTerrorists})
+	assert.True(t, p.Match.Rounds[0].Knife)
+	assert.Equal(t, 0, p.Match.Rounds[0].Half)
+	assert.Equal(t, 0, p.Match.Players.Players[0].Firstkills)
+	assert.Equal(t, 0, p.Match.General.ScoreA)
+	assert.Equal(t, 1, p.state.MatchStartRound)
+	assert.NotNil(t, p.state.KnifeScores)
+
+	// Rounds with shots fired are played out
+	ak := common.NewEquipment(common.EqAK47)
+	fire(p, events.RoundStart{}, events.WeaponFire{Shooter: a, Weapon: ak},
+		events.Kill{Killer: a, Victim: b, Weapon: ak}, events.RoundEnd{Winner: common.TeamCounterTerrorists})
+	assert.False(t, p.Match.Rounds[1].Knife)
+	assert.Equal(t, 1, p.Match.Rounds[1].Half)
+	assert.Equal(t, 1, p.Match.General.ScoreA)
+}
+
+func TestKnifeRoundRestart(t *testing.T) {
+	ks := playerScore{Kills: 2, Deaths: 1, Assists: 1, MVPs: 1}
+
+	// Without a restart the knife round is taken off the scoreboard
+	kills, deaths, assists, mvps := ks.subtract(20, 15, 4, 3)
+	assert.Equal(t, []int{18, 14, 3, 2}, []int{kills, deaths, assists, mvps})
+	// A scoreboard below the one of the knife round was reset
+	kills, deaths, assists, mvps = ks.subtract(1, 15, 4, 3)
+	assert.Equal(t, []int{1, 15, 4, 3}, []int{kills, deaths, assists, mvps})
+
+	// Restarting the game after the knife round resets the scoreboard
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+	knife := common.NewEquipment(common.EqKnife)
+	fire(p, events.RoundStart{}, events.Kill{Killer: a, Victim: b, Weapon: knife},
+		events.RoundEnd{Winner: common.TeamCounterTerrorists})
+	assert.NotNil(t, p.state.KnifeScores)
+	fire(p, events.MatchStart{})
+	assert.Nil(t, p.state.KnifeScores)
+	assert.Equal(t, 1, p.state.MatchStartRound)
+
+	p.state.KnifeScores = map[uint64]playerScore{1: ks}
+	p.handlerMatchStartedChanged(events.MatchStartedChanged{NewIsStarted: true})
+	assert.Nil(t, p.state.KnifeScores)
+}
+
+func TestTeamScoresKnifeRound(t *testing.T) {
+	var unstarted DemoParser
+	_, _, ok := unstarted.teamScores(1, 0)
+	assert.False(t, ok)
+
+	// Team A wins the knife round on CT and plays T after it
+	a, b := testPlayers()
+	p := newTestParser(a, b)
+	knife := common.NewEquipment(common.EqKnife)
+	fire(p, events.RoundStart{}, events.Kill{Killer: a, Victim: b, Weapon: knife},
+		events.RoundEnd{Winner: common.TeamCounterTerrorists})
+	p.state.TeamA = common.TeamTerrorists
+	scoreA, scoreB, _ := p.teamScores(2, 4)
+	assert.Equal(t, []int{3, 2}, []int{scoreA, scoreB})
+
+	// A restart resets the game score
+	fire(p, events.MatchStart{})
+	p.state.TeamA = common.TeamTerrorists
+	scoreA, scoreB, _ = p.teamScores(2, 4)
+	assert.Equal(t, []int{4, 2}, []int{scoreA, scoreB})
+}
+
+func TestSetPeriodScores(t *testing.T) {
+	p := NewDemoParser()
+	p.Match = &Match{}
+	ct := common.TeamCounterTerrorists
+	for _, r := range []ScoreboardRound{
+		{Knife: true, TeamWon: ct, AWonRound: true},
+		{Half: 1, TeamWon: ct, AWonRound: true},
+		{Half: 1, TeamWon: ct},
+		{Half: 2, TeamWon: ct, AWonRound: true},
+		{Half: 1, Overtime: 1, TeamWon: ct},
+		{Half: 2, Overtime: 1, TeamWon: ct, Incomplete: true},
+	} {
+		p.Match.Rounds = append(p.Match.Rounds, r)
+	}
+
+	p.setPeriodScores()
+	assert.Equal(t, []PeriodScore{{1, 1}, {1, 0}}, p.Match.General.Halves)
+	assert.Equal(t, []PeriodScore{{0, 1}}, p.Match.General.Overtimes)
+}
@@ -0,0 +1,150 @@
+package demostats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// discordEmbedColor is the sidebar color of the match embed
+const discordEmbedColor = 0xf0a330
+
+// DiscordMessage is the body of a discord webhook execution
+type DiscordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []DiscordEmbed `json:"embeds"`
+}
+
+// DiscordEmbed is a single rich embed of a discord message
+type DiscordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+// DiscordEmbedField is a name/value field of an embed
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// DiscordEmbedFooter is the footer text of an embed
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// DiscordMessage returns the match summary formatted as a discord webhook
+// message with a single embed
+func (is InfoStruct) DiscordMessage() DiscordMessage {
+
+	embed := DiscordEmbed{
+		Title:       fmt.Sprintf("%s  %d : %d", is.General.MapName, is.General.ScoreA, is.General.ScoreB),
+		Description: is.discordResult(),
+		Color:       discordEmbedColor,
+		Fields: []DiscordEmbedField{
+			{Name: "Team A", Value: discordScoreboard(is.Players.A())},
+			{Name: "Team B", Value: discordScoreboard(is.Players.B())},
+		},
+	}
+
+	if mvp := is.Players.MVP(); mvp != nil {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:  "MVP",
+			Value: fmt.Sprintf("**%s** - %d MVPs, %d kills, %.2f rating, %.1f ADR", mvp.Name, mvp.MVPs, mvp.Kills, mvp.Rating, mvp.Adr),
+		})
+	}
+
+	if notable := is.Players.notableEvents(); len(notable) > 0 {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:  "Highlights",
+			Value: strings.Join(notable, "\n"),
+		})
+	}
+
+	if is.MatchID != "" {
+		embed.Footer = &DiscordEmbedFooter{Text: "Match " + is.MatchID}
+	}
+
+	if !is.General.MatchTime.IsZero() {
+		embed.Timestamp = is.General.MatchTime.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return DiscordMessage{
+		Username: "csgo-demo-stats",
+		Embeds:   []DiscordEmbed{embed},
+	}
+}
+
+func (is InfoStruct) discordResult() string {
+	switch {
+	case is.General.ScoreA > is.General.ScoreB:
+		return "Team A wins"
+	case is.General.ScoreB > is.General.ScoreA:
+		return "Team B wins"
+	default:
+		return "Draw"
+	}
+}
+
+// MVP returns the player with the most round MVPs, ties go to the higher
+// rating. nil if there are no players
+func (sp ScoreboardPlayers) MVP() *ScoreboardPlayer {
+	var mvp *ScoreboardPlayer
+	for k := range sp.Players {
+		p := &sp.Players[k]
+		if mvp == nil || p.MVPs > mvp.MVPs || (p.MVPs == mvp.MVPs && p.Rating > mvp.Rating) {
+			mvp = p
+		}
+	}
+	return mvp
+}
+
+// notableEvents lists aces and clutches of all players
+func (sp ScoreboardPlayers) notableEvents() []string {
+	out := []string{}
+	for _, p := range sp.Players {
+		if p.Rounds5K > 0 {
+			out = append(out, fmt.Sprintf(":fire: **%s** ace x%d", p.Name, p.Rounds5K))
+		}
+		clutches := []struct {
+			count    int
+			opponent int
+		}{
+			{p.Roundswonv5, 5},
+			{p.Roundswonv4, 4},
+			{p.Roundswonv3, 3},
+			{p.Roundswonv2, 2},
+		}
+		for _, c := range clutches {
+			if c.count > 0 {
+				out = append(out, fmt.Sprintf(":muscle: **%s** 1v%d clutch x%d", p.Name, c.opponent, c.count))
+			}
+		}
+	}
+	return out
+}
+
+// discordScoreboard renders the players of one team sorted by rating as a
+// monospaced table
+func discordScoreboard(players []ScoreboardPlayer) string {
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Rating > players[j].Rating
+	})
+
+	var b strings.Builder
+	b.WriteString("```\n")
+	fmt.Fprintf(&b, "%-16s %3s %3s %3s %5s %4s\n", "Player", "K", "D", "A", "ADR", "RTG")
+	for _, p := range players {
+		name := p.Name
+		if runes := []rune(name); len(runes) > 16 {
+			name = string(runes[:16])
+		}
+		fmt.Fprintf(&b, "%-16s %3d %3d %3d %5.1f %4.2f\n", name, p.Kills, p.Deaths, p.Assists, p.Adr, p.Rating)
+	}
+	b.WriteString("```")
+	return b.String()
+}
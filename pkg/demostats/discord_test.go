@@ -0,0 +1,55 @@
+package demostats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscordMessage(t *testing.T) {
+	is := InfoStruct{
+		MatchID: "42",
+		General: ScoreboardGeneral{MapName: "de_inferno", ScoreA: 16, ScoreB: 14},
+		Players: ScoreboardPlayers{Players: []ScoreboardPlayer{
+			{Name: "low", IsAMember: true, Rating: 0.8, MVPs: 3},
+			{Name: "high", IsAMember: true, Rating: 1.6, MVPs: 5, Rounds5K: 1},
+			{Name: "enemy", Rating: 1.1, MVPs: 5, Roundswonv3: 2},
+		}},
+	}
+
+	msg := is.DiscordMessage()
+	assert.Len(t, msg.Embeds, 1)
+
+	embed := msg.Embeds[0]
+	assert.Equal(t, "de_inferno  16 : 14", embed.Title)
+	assert.Equal(t, "Team A wins", embed.Description)
+
+	// Team A sorted by rating
+	teamA := embed.Fields[0].Value
+	assert.True(t, strings.Index(teamA, "high") < strings.Index(teamA, "low"))
+	assert.Contains(t, embed.Fields[1].Value, "enemy")
+
+	assert.Equal(t, "MVP", embed.Fields[2].Name)
+	assert.Contains(t, embed.Fields[2].Value, "**high** - 5 MVPs")
+
+	assert.Equal(t, "Highlights", embed.Fields[3].Name)
+	assert.Contains(t, embed.Fields[3].Value, "**high** ace x1")
+	assert.Contains(t, embed.Fields[3].Value, "**enemy** 1v3 clutch x2")
+}
+
+func TestMVP(t *testing.T) {
+	sp := ScoreboardPlayers{Players: []ScoreboardPlayer{
+		{Name: "rating", Rating: 1.8, MVPs: 2},
+		{Name: "mvps", Rating: 0.9, MVPs: 4},
+	}}
+	assert.Equal(t, "mvps", sp.MVP().Name)
+	assert.Nil(t, ScoreboardPlayers{}.MVP())
+}
+
+func TestDiscordScoreboardNames(t *testing.T) {
+	// Long names are cut after 16 characters, not bytes
+	board := discordScoreboard([]ScoreboardPlayer{{Name: "ÄÖÜäöüßÄÖÜäöüßÄÖÜ"}})
+	assert.Contains(t, board, "\nÄÖÜäöüßÄÖÜäöüßÄÖ   0 ")
+	assert.NotContains(t, board, "\ufffd")
+}
@@ -0,0 +1,226 @@
+package demostats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export tables available for csv and xlsx output
+const (
+	ExportPlayers = "players"
+	ExportRounds  = "rounds"
+	ExportKills   = "kills"
+	ExportChat    = "chat"
+)
+
+// ExportTables lists the tables in the order they appear in a workbook. The
+// chat sheet is added after them if the chat was recorded
+var ExportTables = []string{ExportPlayers, ExportRounds, ExportKills}
+
+// Records returns the rows of the given export table including a header row.
+// Steam IDs are returned as strings to keep their precision in spreadsheets
+func (is InfoStruct) Records(table string) ([][]interface{}, error) {
+	switch table {
+	case ExportPlayers:
+		return is.PlayerRecords(), nil
+	case ExportRounds:
+		return is.RoundRecords(), nil
+	case ExportKills:
+		return is.KillRecords(), nil
+	case ExportChat:
+		return is.ChatRecords(), nil
+	}
+	return nil, fmt.Errorf("unknown export table: %s", table)
+}
+
+// PlayerRecords returns one row per player of the scoreboard
+func (is InfoStruct) PlayerRecords() [][]interface{} {
+	out := [][]interface{}{{
+		"steamid64", "steamid", "name", "team", "isbot", "rank",
+		"kills", "deaths", "assists", "mvps", "kd", "adr", "kast", "rws", "rating", "kpr",
+		"rounds_played", "substitute",
+		"headshots", "hsprecent", "firstkills", "firstdeaths",
+		"tradekills", "tradedeaths", "tradefirstkills", "tradefirstdeaths",
+		"roundswonv5", "roundswonv4", "roundswonv3", "roundswonv2",
+		"rounds5k", "rounds4k", "rounds3k", "rounds2k", "rounds1k",
+		"effFlashes", "efpr", "flashDuration",
+	}}
+
+	for _, p := range is.Players.Players {
+		out = append(out, []interface{}{
+			formatSteamID(p.Steamid64), p.SteamId, p.Name, p.TeamChar, p.IsBot, p.Rank,
+			p.Kills, p.Deaths, p.Assists, p.MVPs,
+			p.Kd, p.Adr, p.Kast, p.Rws, p.Rating, p.Kpr,
+			p.RoundsPlayed, p.Substitute,
+			p.Headshots, p.Hsprecent, p.Firstkills, p.Firstdeaths,
+			p.Tradekills, p.Tradedeaths, p.Tradefirstkills, p.Tradefirstdeaths,
+			p.Roundswonv5, p.Roundswonv4, p.Roundswonv3, p.Roundswonv2,
+			p.Rounds5K, p.Rounds4K, p.Rounds3K, p.Rounds2K, p.Rounds1K,
+			p.EffFlashes, p.Efpr, p.FlashDuration,
+		})
+	}
+	return out
+}
+
+// RoundRecords returns one row per round of the match
+func (is InfoStruct) RoundRecords() [][]interface{} {
+	out := [][]interface{}{{
+		"round", "a_won_round", "score_a", "score_b", "team_won", "win_reason",
+		"kills_a", "kills_b", "bomb_planter", "bomb_defuser", "half", "overtime", "knife",
+	}}
+
+	for k, r := range is.Rounds {
+		out = append(out, []interface{}{
+			k + 1, r.AWonRound, r.ScoreA, r.ScoreB, int(r.TeamWon), int(r.WinReason),
+			len(r.AKills), len(r.BKills),
+			formatSteamID(r.BombPlanter), formatSteamID(r.BombDefuser), r.Half, r.Overtime, r.Knife,
+		})
+	}
+	return out
+}
+
+// KillRecords returns one row per kill of the match, ordered by round and time
+func (is InfoStruct) KillRecords() [][]interface{} {
+	out := [][]interface{}{{
+		"round", "time", "team", "killer", "killer_steamid64", "victim", "victim_steamid64",
+		"assister", "assister_steamid64", "weapon", "weapon_name", "is_headshot", "bot_controlled",
+	}}
+
+	type teamKill struct {
+		team string
+		kill RoundKill
+	}
+
+	for k, r := range is.Rounds {
+		kills := []teamKill{}
+		for _, v := range r.AKills {
+			kills = append(kills, teamKill{"A", v})
+		}
+		for _, v := range r.BKills {
+			kills = append(kills, teamKill{"B", v})
+		}
+		sort.SliceStable(kills, func(i, j int) bool {
+			return kills[i].kill.Time < kills[j].kill.Time
+		})
+
+		for _, v := range kills {
+			assister, assisterID := "", ""
+			if v.kill.Assister != nil {
+				assister = v.kill.Assister.Name
+				assisterID = formatSteamID(v.kill.Assister.Steamid64)
+			}
+			out = append(out, []interface{}{
+				k + 1, v.kill.Time.String(), v.team,
+				v.kill.Killer.Name, formatSteamID(v.kill.Killer.Steamid64),
+				v.kill.Victim.Name, formatSteamID(v.kill.Victim.Steamid64),
+				assister, assisterID,
+				int(v.kill.KillerWeapon), v.kill.KillerWeapon.String(), v.kill.IsHeadshot, v.kill.BotControlled,
+			})
+		}
+	}
+	return out
+}
+
+// ChatRecords returns one row per recorded chat message
+func (is InfoStruct) ChatRecords() [][]interface{} {
+	out := [][]interface{}{{
+		"round", "time", "steamid64", "name", "team", "all_chat", "text", "matches",
+	}}
+
+	for _, m := range is.Chat {
+		out = append(out, []interface{}{
+			m.Round, m.Time.String(), formatSteamID(m.Steamid64), m.Name, m.TeamChar, m.AllChat, m.Text,
+			strings.Join(m.Matches, " "),
+		})
+	}
+	return out
+}
+
+// WriteCSV writes the given export table as csv
+func (is InfoStruct) WriteCSV(w io.Writer, table string) error {
+	records, err := is.Records(table)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(records))
+	for k, record := range records {
+		rows[k] = make([]string, len(record))
+		for col, v := range record {
+			rows[k][col] = formatCell(escapeCell(v))
+		}
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// WriteXLSX writes a workbook with one sheet per export table
+func (is InfoStruct) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+
+	tables := ExportTables
+	if len(is.Chat) > 0 {
+		tables = append(tables[:len(tables):len(tables)], ExportChat)
+	}
+	for k, table := range tables {
+		if k == 0 {
+			f.SetSheetName(f.GetSheetName(0), table)
+		} else {
+			f.NewSheet(table)
+		}
+
+		records, err := is.Records(table)
+		if err != nil {
+			return err
+		}
+
+		for row, record := range records {
+			cell, err := excelize.CoordinatesToCellName(1, row+1)
+			if err != nil {
+				return err
+			}
+			escaped := make([]interface{}, len(record))
+			for col, v := range record {
+				escaped[col] = escapeCell(v)
+			}
+			if err := f.SetSheetRow(table, cell, &escaped); err != nil {
+				return err
+			}
+		}
+	}
+
+	return f.Write(w)
+}
+
+// escapeCell prefixes text cells a spreadsheet would read as a formula, like
+// player names or chat messages starting with "=", with a quote
+func escapeCell(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return v
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return v
+}
+
+func formatCell(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func formatSteamID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
@@ -0,0 +1,105 @@
+package demostats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func exportTestMatch() InfoStruct {
+	a := &ScoreboardPlayer{Name: "a", Steamid64: 76561197990376443, IsAMember: true}
+	b := &ScoreboardPlayer{Name: "b", Steamid64: 76561197971293742}
+
+	return InfoStruct{
+		Players: ScoreboardPlayers{Players: []ScoreboardPlayer{*a, *b}},
+		Rounds: []ScoreboardRound{{
+			AWonRound: true,
+			ScoreA:    1,
+			AKills:    []RoundKill{{Time: 20 * time.Second, Killer: a, Victim: b, KillerWeapon: common.EqAK47, IsHeadshot: true}},
+			BKills:    []RoundKill{{Time: 10 * time.Second, Killer: b, Victim: a, Assister: b, KillerWeapon: common.EqAWP}},
+		}},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	is := exportTestMatch()
+
+	var buf bytes.Buffer
+	assert.NoError(t, is.WriteCSV(&buf, ExportKills))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	// Kills are ordered by time within a round
+	assert.Equal(t, []string{"1", "10s", "B", "b", "76561197971293742", "a", "76561197990376443",
+		"b", "76561197971293742", "309", "AWP", "false", "false"}, rows[1])
+	assert.Equal(t, "AK-47", rows[2][10])
+
+	assert.Error(t, is.WriteCSV(&buf, "unknown"))
+}
+
+func TestWriteXLSX(t *testing.T) {
+	is := exportTestMatch()
+
+	var buf bytes.Buffer
+	assert.NoError(t, is.WriteXLSX(&buf))
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, ExportTables, f.GetSheetList())
+
+	players, err := f.GetRows(ExportPlayers)
+	assert.NoError(t, err)
+	assert.Len(t, players, 3)
+	assert.Equal(t, "76561197990376443", players[1][0])
+}
+
+func TestWriteXLSXChat(t *testing.T) {
+	is := exportTestMatch()
+	is.Chat = []ChatMessage{{Round: 1, Time: 5 * time.Second, Steamid64: 76561197990376443, Name: "a", TeamChar: "A", Text: "gl hf"}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, is.WriteXLSX(&buf))
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, append(ExportTables[:len(ExportTables):len(ExportTables)], ExportChat), f.GetSheetList())
+
+	chat, err := f.GetRows(ExportChat)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "5s", "76561197990376443", "a", "A", "0", "gl hf"}, chat[1])
+}
+
+func TestExportEscapesFormulas(t *testing.T) {
+	is := exportTestMatch()
+	is.Players.Players[0].Name = "=HYPERLINK(\"x\")"
+	is.Chat = []ChatMessage{{Round: 1, Steamid64: 76561197990376443, Name: "a", Text: "@SUM(1)"}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, is.WriteCSV(&buf, ExportChat))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, "'@SUM(1)", rows[1][6])
+
+	buf.Reset()
+	assert.NoError(t, is.WriteXLSX(&buf))
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	players, err := f.GetRows(ExportPlayers)
+	assert.NoError(t, err)
+	assert.Equal(t, "'=HYPERLINK(\"x\")", players[1][2])
+	chat, err := f.GetRows(ExportChat)
+	assert.NoError(t, err)
+	assert.Equal(t, "'@SUM(1)", chat[1][6])
+
+	assert.Equal(t, -1.5, escapeCell(-1.5))
+	assert.Equal(t, "'-1", escapeCell("-1"))
+	assert.Equal(t, "'\tx", escapeCell("\tx"))
+	assert.Equal(t, "a", escapeCell("a"))
+}
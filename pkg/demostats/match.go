@@ -1,27 +1,34 @@
-package main
+package demostats
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"hash/fnv"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	// "github.com/golang/geo/r3"
 	common "github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
 	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
-	log "github.com/sirupsen/logrus"
 )
 
 // InfoStruct holds the data of one demo file in it's parsed form
 type InfoStruct struct {
-	MatchID    string            `json:"match_id"     db:"match_id"`
-	MatchValid bool              `json:"match_valid"  db:"match_valid"`
-	General    ScoreboardGeneral `json:"general"      db:"general"`
-	Players    ScoreboardPlayers `json:"players"      db:"players"`
-	RdDamages  PlayerRoundDamage `json:"rd_damages"   db:"rd_damages"`
-	Rounds     []ScoreboardRound `json:"rounds"       db:"rounds"`
+	SchemaVersion int               `json:"schema_version" db:"schema_version"`
+	MatchID       string            `json:"match_id"       db:"match_id"`
+	MatchValid    bool              `json:"match_valid"    db:"match_valid"`
+	General       ScoreboardGeneral `json:"general"        db:"general"`
+	Players       ScoreboardPlayers `json:"players"        db:"players"`
+	RdDamages     PlayerRoundDamage `json:"rd_damages"     db:"rd_damages"`
+	Rounds        []ScoreboardRound `json:"rounds"         db:"rounds"`
+	Warnings      []string          `json:"warnings"       db:"warnings"`
+	Errors        []string          `json:"errors"         db:"errors"`
+	Chat          []ChatMessage     `json:"chat,omitempty" db:"chat"`
+	Connections   []Connection      `json:"connections"    db:"connections"`
 
 	// Duels             [][]int
 	// HeatmapsImageURLs []string
@@ -47,14 +54,40 @@ func (is *InfoStruct) Scan(value interface{}) error {
 
 // ScoreboardGeneral holds general information about the match
 type ScoreboardGeneral struct {
-	Winner        int           `json:"winner"         db:"winner"`
-	ScoreA        int           `json:"score_a"        db:"score_a"`
-	ScoreB        int           `json:"score_b"        db:"score_b"`
-	MapName       string        `json:"map_name"       db:"map_name"`
-	MapIconURL    string        `json:"map_icon_url"   db:"map_icon_url"`
-	MatchTime     time.Time     `json:"match_time"    db:"match_time"`
-	MatchDuration time.Duration `json:"match_duration" db:"match_duration"`
-	DemoLinkURL   string        `json:"demo_link_url"  db:"demo_link_url"`
+	Winner         int           `json:"winner"          db:"winner"`
+	ScoreA         int           `json:"score_a"         db:"score_a"`
+	ScoreB         int           `json:"score_b"         db:"score_b"`
+	MapName        string        `json:"map_name"        db:"map_name"`
+	MapIconURL     string        `json:"map_icon_url"    db:"map_icon_url"`
+	MatchTime      time.Time     `json:"match_time"      db:"match_time"`
+	MatchDuration  time.Duration `json:"match_duration"  db:"match_duration"`
+	DemoLinkURL    string        `json:"demo_link_url"   db:"demo_link_url"`
+	ServerName     string        `json:"server_name"     db:"server_name"`
+	ClientName     string        `json:"client_name"     db:"client_name"`
+	DemoType       DemoType      `json:"demo_type"       db:"demo_type"`
+	TickRate       float64       `json:"tick_rate"       db:"tick_rate"`
+	PlaybackTicks  int           `json:"playback_ticks"  db:"playback_ticks"`
+	PlaybackFrames int           `json:"playback_frames" db:"playback_frames"`
+	DemoDuration   time.Duration `json:"demo_duration"   db:"demo_duration"`
+	GameMode       GameMode      `json:"game_mode"       db:"game_mode"`
+	MaxRounds      int           `json:"max_rounds"      db:"max_rounds"`
+	Halves         []PeriodScore `json:"halves"          db:"halves"`
+	Overtimes      []PeriodScore `json:"overtimes"       db:"overtimes"`
+}
+
+// DemoType tells demos recorded by GOTV and by a player apart
+type DemoType string
+
+// Demo types
+const (
+	DemoTypeGOTV DemoType = "gotv"
+	DemoTypePOV  DemoType = "pov"
+)
+
+// PeriodScore holds the rounds won by each team in a half or an overtime
+type PeriodScore struct {
+	ScoreA int `json:"score_a" db:"score_a"`
+	ScoreB int `json:"score_b" db:"score_b"`
 }
 
 // RoundKill holds information about a kill that happenend during the match
@@ -68,6 +101,29 @@ type RoundKill struct {
 	Killer             *ScoreboardPlayer    `json:"killer"               db:"killer"`
 	Assister           *ScoreboardPlayer    `json:"assister"             db:"assister"`
 	KillerWeapon       common.EquipmentType `json:"weapon"               db:"weapon"`
+	BotControlled      bool                 `json:"bot_controlled"       db:"bot_controlled"`
+}
+
+// ChatMessage holds a chat message sent during the match
+type ChatMessage struct {
+	Round     int           `json:"round"      db:"round"`
+	Time      time.Duration `json:"time"       db:"time"`
+	Steamid64 uint64        `json:"steamid64"  db:"steamid64"`
+	Name      string        `json:"name"       db:"name"`
+	TeamChar  string        `json:"team"       db:"team"`
+	AllChat   bool          `json:"all_chat"   db:"all_chat"`
+	Text      string        `json:"text"       db:"text"`
+	Matches   []string      `json:"matches,omitempty" db:"matches"`
+}
+
+// Connection holds a player connecting to or disconnecting from the server
+// after the match started
+type Connection struct {
+	Round     int           `json:"round"     db:"round"`
+	Time      time.Duration `json:"time"      db:"time"`
+	Steamid64 uint64        `json:"steamid64" db:"steamid64"`
+	Name      string        `json:"name"      db:"name"`
+	Connected bool          `json:"connected" db:"connected"`
 }
 
 func allWeapons() []common.EquipmentType {
@@ -196,7 +252,7 @@ func (is *InfoStruct) Damages() interface{} {
 		for k2, v := range player.PlayerDamages.Damages {
 			vicNum, err := is.Players.PlayerNumByID(k2)
 			if err != nil {
-				panic(err)
+				continue
 			}
 
 			if is.Players.Players[vicNum].IsBot {
@@ -320,6 +376,7 @@ func (rk *RoundKill) MarshalJSON() ([]byte, error) {
 		Assister           *ScoreboardPlayer    `json:"assister"             db:"assister"`
 		KillerWeapon       common.EquipmentType `json:"weapon"               db:"weapon"`
 		KillerWeaponName   string               `json:"weapon_name"          db:"weapon_name"`
+		BotControlled      bool                 `json:"bot_controlled"       db:"bot_controlled"`
 	}{
 
 		Time:               rk.Time,
@@ -332,21 +389,21 @@ func (rk *RoundKill) MarshalJSON() ([]byte, error) {
 		Assister:           rk.Assister,
 		KillerWeapon:       rk.KillerWeapon,
 		KillerWeaponName:   rk.KillerWeapon.String(),
+		BotControlled:      rk.BotControlled,
 	})
 }
 
-//GetMatchInfo parses a demo file and returns a infostruct containing it's data
-func GetMatchInfo(body io.ReadCloser) (*InfoStruct, error) {
-	p := NewDemoParser()
-	var info InfoStruct
-	err := p.Parse(body, &info)
-	return p.Match, err
+// GetMatchInfo parses a demo file and returns a infostruct containing it's data
+func GetMatchInfo(body io.Reader) (*InfoStruct, error) {
+	return Parse(context.Background(), body, Options{})
 }
 
+// GetMatchInfoFromDisk parses a demo file from disk. The match id is taken
+// from the file name
 func GetMatchInfoFromDisk(path string) (InfoStruct, error) {
 	p := NewDemoParser()
 	var info InfoStruct
-	err := p.ParseFromDisk(path, &info)
+	err := p.ParseFromDisk(context.Background(), path, &info)
 	return info, err
 }
 
@@ -355,6 +412,38 @@ func (is InfoStruct) GetScoreboard() ScoreboardPlayers {
 	return is.Players
 }
 
+// MatchSummary holds the short form of a match as sent to webhooks
+type MatchSummary struct {
+	MatchID    string            `json:"match_id"`
+	MapName    string            `json:"map_name"`
+	ScoreA     int               `json:"score_a"`
+	ScoreB     int               `json:"score_b"`
+	TopFragger *ScoreboardPlayer `json:"top_fragger"`
+}
+
+// Summary returns the summary of a match
+func (is InfoStruct) Summary() MatchSummary {
+	return MatchSummary{
+		MatchID:    is.MatchID,
+		MapName:    is.General.MapName,
+		ScoreA:     is.General.ScoreA,
+		ScoreB:     is.General.ScoreB,
+		TopFragger: is.Players.TopFragger(),
+	}
+}
+
+// TopFragger returns the player with the most kills, nil if there are no
+// players
+func (sp ScoreboardPlayers) TopFragger() *ScoreboardPlayer {
+	var top *ScoreboardPlayer
+	for k := range sp.Players {
+		if top == nil || sp.Players[k].Kills > top.Kills {
+			top = &sp.Players[k]
+		}
+	}
+	return top
+}
+
 // PlayerNumByID returns the a players's position in the Players[] list of a
 // match
 func (sp ScoreboardPlayers) PlayerNumByID(steamID uint64) (int, error) {
@@ -426,6 +515,28 @@ func (sp ScoreboardPlayers) B() []ScoreboardPlayer {
 	return out
 }
 
+// playerID returns the id a player is recorded by. Bots have no steam id,
+// they get a synthetic id instead: the FNV-1a hash of their name, which
+// stays the same when the bot is kicked and added again. Synthetic ids are
+// below 2^32 and never collide with a steam id
+func playerID(pl *common.Player) uint64 {
+	if !pl.IsBot {
+		return pl.SteamID64
+	}
+	h := fnv.New32a()
+	h.Write([]byte(pl.Name))
+	return uint64(h.Sum32())
+}
+
+// botName returns the scoreboard name of a bot, prefixed with BOT like in
+// game
+func botName(pl *common.Player) string {
+	if strings.HasPrefix(pl.Name, "BOT") {
+		return pl.Name
+	}
+	return "BOT " + pl.Name
+}
+
 func (p *DemoParser) playerByID(player *common.Player) *ScoreboardPlayer {
 
 	if player == nil {
@@ -433,13 +544,16 @@ func (p *DemoParser) playerByID(player *common.Player) *ScoreboardPlayer {
 	}
 
 	for _, v := range p.Match.Players.Players {
-		if v.Steamid64 == player.SteamID64 {
+		if v.Steamid64 == playerID(player) {
 			return &v
 		}
 	}
 
-	log.Warning("Created new player for ID:", player.SteamID64)
+	p.warn("created new player for id ", playerID(player))
 	newplayer := p.NewScoreBoardPlayer(player)
+	// Players missing from the lineup at the start of the match came in
+	// later as a substitute
+	newplayer.Substitute = p.matchStarted()
 	p.Match.Players.Players = append(p.Match.Players.Players, newplayer)
 
 	return &newplayer
@@ -467,27 +581,37 @@ type WeaponStats struct {
 	Hits map[common.EquipmentType]int `json:"hits" db:"hits"`
 }
 
+// equipmentType returns the type of a weapon, EqUnknown for events without
+// a weapon
+func equipmentType(w *common.Equipment) common.EquipmentType {
+	if w == nil {
+		return common.EqUnknown
+	}
+	return w.Type
+}
+
 func (ws *WeaponStats) addKill(e events.Kill) {
-	ws.Kills[e.Weapon.Type]++
+	ws.Kills[equipmentType(e.Weapon)]++
 }
 
 func (ws *WeaponStats) addHeadshot(e events.Kill) {
 	if e.IsHeadshot {
-		ws.Headshots[e.Weapon.Type]++
+		ws.Headshots[equipmentType(e.Weapon)]++
 	}
 }
 
 func (ws *WeaponStats) addDamage(e events.PlayerHurt) {
-	ws.Damage[e.Weapon.Type] += e.HealthDamage
+	ws.Damage[equipmentType(e.Weapon)] += e.HealthDamage
 }
 
 func (ws *WeaponStats) addShot(e events.WeaponFire) {
-	ws.Shots[e.Weapon.Type]++
-	ws.Accuracy[e.Weapon.Type] = (ws.getHits(e.Weapon.Type) * 100) / ws.getShots(e.Weapon.Type)
+	w := equipmentType(e.Weapon)
+	ws.Shots[w]++
+	ws.Accuracy[w] = (ws.getHits(w) * 100) / ws.getShots(w)
 }
 
 func (ws *WeaponStats) addHit(e events.PlayerHurt) {
-	ws.Hits[e.Weapon.Type]++
+	ws.Hits[equipmentType(e.Weapon)]++
 }
 
 func (ws WeaponStats) getKills(w common.EquipmentType) int {
@@ -515,6 +639,47 @@ func (ws WeaponStats) getHits(w common.EquipmentType) int {
 	return ws.Hits[w]
 }
 
+// addClutch counts a kill of the last player alive against the opponents
+// left, more than 5 count as 1v5
+func (sp *ScoreboardPlayer) addClutch(opponents int) {
+	switch {
+	case opponents >= 5:
+		sp.Roundswonv5++
+	case opponents == 4:
+		sp.Roundswonv4++
+	case opponents == 3:
+		sp.Roundswonv3++
+	case opponents == 2:
+		sp.Roundswonv2++
+	}
+}
+
+// clone returns a copy of the player that shares no maps with it
+func (sp ScoreboardPlayer) clone() ScoreboardPlayer {
+	c := sp
+	c.WeaponStats = WeaponStats{
+		Kills:     copyCounts(sp.WeaponStats.Kills),
+		Headshots: copyCounts(sp.WeaponStats.Headshots),
+		Accuracy:  copyCounts(sp.WeaponStats.Accuracy),
+		Damage:    copyCounts(sp.WeaponStats.Damage),
+		Shots:     copyCounts(sp.WeaponStats.Shots),
+		Hits:      copyCounts(sp.WeaponStats.Hits),
+	}
+	c.PlayerDamages.Damages = make(map[uint64]int, len(sp.PlayerDamages.Damages))
+	for k, v := range sp.PlayerDamages.Damages {
+		c.PlayerDamages.Damages[k] = v
+	}
+	return c
+}
+
+func copyCounts(m map[common.EquipmentType]int) map[common.EquipmentType]int {
+	c := make(map[common.EquipmentType]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
 func (sp *ScoreboardPlayer) addDamage(damage int, victim *ScoreboardPlayer) {
 	sp.PlayerDamages.Damages[victim.Steamid64] += damage
 }
@@ -556,6 +721,9 @@ type ScoreboardPlayer struct {
 	KastRounds       int           `json:"kastRounds" db:"kastRounds"`
 	Rws              float64       `json:"rws" db:"rws"`
 	Rating           float64       `json:"rating" db:"rating"`
+	Kpr              float64       `json:"kpr" db:"kpr"`
+	RoundsPlayed     int           `json:"rounds_played" db:"rounds_played"`
+	Substitute       bool          `json:"substitute" db:"substitute"`
 	Headshots        int           `json:"headshots" db:"headshots"`
 	Hsprecent        float64       `json:"hsprecent" db:"hsprecent"`
 	Firstkills       int           `json:"firstkills" db:"firstkills"`
@@ -567,6 +735,7 @@ type ScoreboardPlayer struct {
 	Roundswonv5      int           `json:"roundswonv5" db:"roundswonv5"`
 	Roundswonv4      int           `json:"roundswonv4" db:"roundswonv4"`
 	Roundswonv3      int           `json:"roundswonv3" db:"roundswonv3"`
+	Roundswonv2      int           `json:"roundswonv2" db:"roundswonv2"`
 	Rounds5K         int           `json:"rounds5k" db:"rounds5k"`
 	Rounds4K         int           `json:"rounds4k" db:"rounds4k"`
 	Rounds3K         int           `json:"rounds3k" db:"rounds3k"`
@@ -596,4 +765,8 @@ type ScoreboardRound struct {
 	WinnerTeam       common.Team           `json:"winner_team" db:"winner_team"`
 	BombPlanter      uint64                `json:"bomb_planter" db:"bomb_planter"`
 	BombDefuser      uint64                `json:"bomb_defuser" db:"bomb_defuser"`
+	Incomplete       bool                  `json:"incomplete" db:"incomplete"`
+	Half             int                   `json:"half" db:"half"`
+	Overtime         int                   `json:"overtime" db:"overtime"`
+	Knife            bool                  `json:"knife" db:"knife"`
 }
@@ -0,0 +1,101 @@
+package demostats
+
+// GameMode is the game mode a match was played in
+type GameMode string
+
+// Game modes told apart by the stats
+const (
+	ModeCompetitive GameMode = "competitive"
+	ModeWingman     GameMode = "wingman"
+	ModeCasual      GameMode = "casual"
+	ModeArmsRace    GameMode = "armsrace"
+	ModeDemolition  GameMode = "demolition"
+	ModeDeathmatch  GameMode = "deathmatch"
+)
+
+// modeSettings holds the rules of a game mode the stats depend on
+type modeSettings struct {
+	// Default round limits if the convars aren't set
+	MaxRounds         int
+	OvertimeMaxRounds int
+
+	// Players per team, the most kills a round counts as multi kill and the
+	// RWS handed out per round depend on it
+	TeamSize int
+
+	// Fewest opponents left for a kill of the last player alive to count as
+	// a clutch, more than 5 count as 1v5
+	MinClutch int
+}
+
+// Baselines of the HLTV 1.0 rating taken from competitive matches: average
+// kills per round, average survived rounds per round and the average value
+// calculated from rounds with multiple kills. There are none published for
+// other modes, their rating is calculated with these as well
+const (
+	averageKpr = 0.679
+	averageSpr = 0.317
+	averageRmk = 1.277
+)
+
+// competitiveSettings are used for all modes without settings of their own
+var competitiveSettings = modeSettings{
+	MaxRounds:         30,
+	OvertimeMaxRounds: 6,
+	TeamSize:          5,
+	MinClutch:         3,
+}
+
+var gameModeSettings = map[GameMode]modeSettings{
+	ModeCompetitive: competitiveSettings,
+	// Two players per team make for earlier clutches
+	ModeWingman: {
+		MaxRounds:         16,
+		OvertimeMaxRounds: 6,
+		TeamSize:          2,
+		MinClutch:         2,
+	},
+	ModeCasual: {
+		MaxRounds:         15,
+		OvertimeMaxRounds: 6,
+		TeamSize:          10,
+		MinClutch:         3,
+	},
+}
+
+// settings returns the settings of the game mode
+func (m GameMode) settings() modeSettings {
+	if s, ok := gameModeSettings[m]; ok {
+		return s
+	}
+	return competitiveSettings
+}
+
+// rwsShares returns the RWS split among the winners of a round and the part
+// of it given to the bomb planter or defuser. Competitive hands out 100 to
+// 5 players, other team sizes get as much per player so RWS compares across
+// modes
+func (s modeSettings) rwsShares() (round, bomb float64) {
+	round = 100 * float64(s.TeamSize) / 5
+	return round, round * 0.3
+}
+
+// gameModeFromConVars returns the game mode set by the game_type and
+// game_mode convars, false if they aren't set
+func gameModeFromConVars(conVars map[string]string) (GameMode, bool) {
+	switch conVars["game_type"] + "/" + conVars["game_mode"] {
+	case "0/0":
+		return ModeCasual, true
+	case "0/1":
+		return ModeCompetitive, true
+	case "0/2":
+		return ModeWingman, true
+	case "1/0":
+		return ModeArmsRace, true
+	case "1/1":
+		return ModeDemolition, true
+	case "1/2":
+		return ModeDeathmatch, true
+	}
+	return "", false
+}
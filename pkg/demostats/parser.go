@@ -0,0 +1,1490 @@
+package demostats
+
+// https://github.com/markus-wa/demoinfocs-golang/blob/master/examples/print-events/print_events.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+
+	"time"
+
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/common"
+	"github.com/markus-wa/demoinfocs-golang/v2/pkg/demoinfocs/events"
+)
+
+// DemoParser holds all methods to parse a demo file into a infostruct
+type DemoParser struct {
+	parser demoinfocs.Parser
+	Match  *InfoStruct
+	state  parsingState
+
+	// Logger receives the logs of the parse, they carry the match id, map,
+	// tick and round as fields
+	Logger *log.Entry
+
+	// OnProgress is called whenever the parsed percent, the round or the
+	// score changes, see Options.Progress
+	OnProgress func(Progress)
+
+	// OnRoundEnd is called with a snapshot of the match after every round,
+	// see Options.RoundEnd
+	OnRoundEnd func(*InfoStruct)
+
+	// CaptureChat records the chat messages, ChatWords filters them, see
+	// Options.Chat and Options.ChatWords
+	CaptureChat bool
+	ChatWords   []string
+
+	// MatchTime is set as the time of the match, the time of the parse if
+	// zero. See Options.MatchTime
+	MatchTime time.Time
+}
+
+// NewDemoParser constructor for a new demoparser logging to the standard
+// logrus logger
+func NewDemoParser() DemoParser {
+	return DemoParser{
+		Logger: log.NewEntry(log.StandardLogger()),
+		state: parsingState{
+			Round:        0,
+			RoundOngoing: false,
+		},
+	}
+}
+
+// Used while parsing to hold values while going through the ticks
+type parsingState struct {
+	Round        int // Current round
+	RoundOngoing bool
+	RoundsEnded  int // Number of rounds that have ended
+	WarmupKills  []events.Kill
+	TeamA        common.Team
+	Progress     Progress // Last reported progress
+
+	MatchStarted    bool
+	MatchStartRound int           // Round ongoing when the match started
+	MatchStartTime  time.Duration // Demo time the match started at
+	LastRoundEnd    time.Duration // Demo time the last round ended at
+
+	// Players controlling a bot this round by the id of the bot
+	BotControllers map[uint64]*common.Player
+
+	RoundGunFired bool                   // A weapon other than the knife was fired this round
+	KnifeScores   map[uint64]playerScore // Scoreboard of the players after a knife round
+	KnifeWonByA   bool                   // Team A won the knife round, counted in the game score as long as KnifeScores is set
+
+	// Stats and scoreboard of the players when the round started, restored
+	// if the demo ends before the round does
+	RoundStartPlayers []ScoreboardPlayer
+	RoundStartScores  map[uint64]playerScore
+}
+
+// playerScore holds the in-game scoreboard of a player. The one after a
+// knife round is taken off the kills, deaths, assists and MVPs of the match
+// as long as the scoreboard wasn't reset since
+type playerScore struct {
+	Kills, Deaths, Assists, MVPs int
+}
+
+// subtract returns the in-game scoreboard without the knife round. Values
+// below the ones of the knife round can only come from a reset scoreboard
+// and are returned as they are
+func (ks playerScore) subtract(kills, deaths, assists, mvps int) (int, int, int, int) {
+	if kills < ks.Kills || deaths < ks.Deaths || assists < ks.Assists || mvps < ks.MVPs {
+		return kills, deaths, assists, mvps
+	}
+	return kills - ks.Kills, deaths - ks.Deaths, assists - ks.Assists, mvps - ks.MVPs
+}
+
+// Parse starts the parsing process and fills the infostruct with values
+// gathered from the demo file. Parsing stops early and the error of the
+// context is returned when ctx is done
+func (p *DemoParser) Parse(ctx context.Context, body io.Reader, m *InfoStruct) error {
+
+	m.SchemaVersion = SchemaVersion
+	// Register handlers for events we care about
+	p.Match = m
+	var err error
+	p.parser = demoinfocs.NewParser(body)
+	defer p.parser.Close()
+
+	p.parser.RegisterEventHandler(p.handlerKill)
+	p.parser.RegisterEventHandler(p.handlerMatchStart)
+	p.parser.RegisterEventHandler(p.handlerMatchStartedChanged)
+	p.parser.RegisterEventHandler(p.handlerRoundEnd)
+	p.parser.RegisterEventHandler(p.handlerRoundStart)
+	p.parser.RegisterEventHandler(p.handlerRankUpdate)
+	p.parser.RegisterEventHandler(p.handlerPlayerHurt)
+	p.parser.RegisterEventHandler(p.handlerBombPlanted)
+	p.parser.RegisterEventHandler(p.handlerBombDefused)
+	p.parser.RegisterEventHandler(p.handlerBombExplode)
+	p.parser.RegisterEventHandler(p.handlerScoreUpdated)
+	p.parser.RegisterEventHandler(p.handlerWeaponFire)
+	p.parser.RegisterEventHandler(p.handlerPlayerFlashed)
+	p.parser.RegisterEventHandler(p.handlerPlayerConnect)
+	p.parser.RegisterEventHandler(p.handlerPlayerDisconnected)
+	p.parser.RegisterEventHandler(p.handlerBotTakenOver)
+	if p.OnProgress != nil {
+		p.parser.RegisterEventHandler(p.handlerFrameDone)
+	}
+	if p.OnRoundEnd != nil {
+		// Registered after handlerRoundEnd, so the round is complete
+		p.parser.RegisterEventHandler(p.handlerRoundEndSnapshot)
+	}
+	p.log().Debug("registered event handlers")
+	if p.CaptureChat {
+		p.parser.RegisterEventHandler(p.handlerChatMessage)
+	}
+
+	// Stop the demoinfocs parser once the context is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.parser.Cancel()
+		case <-done:
+		}
+	}()
+
+	err = p.parse()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+
+}
+
+// parse runs the demoinfocs parser and calculates the stats. Errors and
+// panics of demoinfocs are converted to the errors of this package
+func (p *DemoParser) parse() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.recoveredError(r)
+		}
+	}()
+
+	// Parse header and set general values
+	if err = p.setGeneral(); err != nil {
+		return err
+	}
+	// Parse the demo returning errors
+	err = demoError(p.parser.ParseToEnd())
+
+	// Keep the stats of truncated demos up to the last completed round
+	if err == ErrTruncatedDemo && p.state.RoundsEnded > 0 {
+		p.markPartial()
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	p.calculate()
+	return nil
+}
+
+// markPartial marks the match as partial after the demo ended unexpectedly.
+// A round that was still ongoing is flagged as incomplete and the stats
+// gathered during it are dropped
+func (p *DemoParser) markPartial() {
+	p.Match.MatchValid = false
+	if p.state.RoundOngoing && p.roundStarted() {
+		p.Match.Rounds[p.state.Round-1].Incomplete = true
+		p.dropIncompleteRound()
+	}
+	p.fail("demo ended unexpectedly, stats only cover the rounds up to this point")
+}
+
+// dropIncompleteRound takes the stats of the players back to the start of the
+// round cut off by the end of the demo, so they cover the same rounds as
+// rounds played. Players that joined during the round never completed one
+// and are dropped
+func (p *DemoParser) dropIncompleteRound() {
+	if p.state.RoundStartPlayers == nil {
+		return
+	}
+	p.Match.Players.Players = append(p.Match.Players.Players[:0], p.state.RoundStartPlayers...)
+	p.Match.RdDamages.RdDamages = NewRdDamages()
+}
+
+// saveRoundStart keeps the stats and the in-game scoreboard of the players at
+// the start of a round
+func (p *DemoParser) saveRoundStart() {
+	p.state.RoundStartPlayers = make([]ScoreboardPlayer, len(p.Match.Players.Players))
+	p.state.RoundStartScores = map[uint64]playerScore{}
+	for k, pl := range p.Match.Players.Players {
+		p.state.RoundStartPlayers[k] = pl.clone()
+		if gp := p.playersBySteamID(pl.Steamid64); gp != nil {
+			p.state.RoundStartScores[pl.Steamid64] = scoreOf(gp)
+		}
+	}
+}
+
+// scoreOf returns the in-game scoreboard of a player
+func scoreOf(pl *common.Player) playerScore {
+	return playerScore{pl.Kills(), pl.Deaths(), pl.Assists(), pl.MVPs()}
+}
+
+// score returns the in-game scoreboard of a player at the end of the last
+// completed round
+func (p *DemoParser) score(pl *common.Player) playerScore {
+	if n := len(p.Match.Rounds); n > 0 && p.Match.Rounds[n-1].Incomplete {
+		return p.state.RoundStartScores[playerID(pl)]
+	}
+	return scoreOf(pl)
+}
+
+// demoError converts errors returned by demoinfocs
+func demoError(err error) error {
+	switch err {
+	case nil, demoinfocs.ErrCancelled:
+		return err
+	case demoinfocs.ErrInvalidFileType:
+		return ErrInvalidFile
+	case demoinfocs.ErrUnexpectedEndOfDemo:
+		return ErrTruncatedDemo
+	}
+	return fmt.Errorf("%w: %v", ErrInternal, err)
+}
+
+// recoveredError converts a panic during parsing to an error
+func (p *DemoParser) recoveredError(r interface{}) error {
+	if r == io.EOF || r == io.ErrUnexpectedEOF {
+		return ErrTruncatedDemo
+	}
+	p.log().Error("recovered from panic while parsing: ", r, "\n", string(debug.Stack()))
+	return fmt.Errorf("%w: %v", ErrInternal, r)
+}
+
+// log returns the logger with the current match id, map, tick and round
+func (p *DemoParser) log() *log.Entry {
+	fields := log.Fields{"round": p.state.Round}
+	if p.Match != nil {
+		fields["match_id"] = p.Match.MatchID
+		fields["map"] = p.Match.General.MapName
+	}
+	if p.parser != nil {
+		fields["tick"] = p.parser.GameState().IngameTick()
+	}
+	return p.Logger.WithFields(fields)
+}
+
+// warn records a problem with the demo data that did not affect the stats
+func (p *DemoParser) warn(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	p.log().Warning(msg)
+	p.Match.Warnings = append(p.Match.Warnings, fmt.Sprintf("round %d: %s", p.state.Round, msg))
+}
+
+// fail records an event that could not be processed and is missing from the
+// stats
+func (p *DemoParser) fail(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	p.log().Error(msg)
+	p.Match.Errors = append(p.Match.Errors, fmt.Sprintf("round %d: %s", p.state.Round, msg))
+}
+
+// roundStarted reports whether the first round has started yet. Events
+// before that have no round to be recorded in
+func (p *DemoParser) roundStarted() bool {
+	return p.state.Round > 0 && p.state.Round <= len(p.Match.Rounds)
+}
+
+// ParseFromDisk parses a demo file from disk, the match id is taken from the
+// file name
+func (p *DemoParser) ParseFromDisk(ctx context.Context, path string, m *InfoStruct) error {
+
+	m.MatchID = MatchIDFromPath(path)
+	var f *os.File
+	var err error
+
+	if f, err = os.Open(path); err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && p.MatchTime.IsZero() {
+		p.MatchTime = matchTimeOfFile(path, info)
+	}
+
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+
+		}
+	}(f)
+
+	return p.Parse(ctx, f, m)
+}
+
+var (
+	// auto0-20210606-183012-1234567-de_dust2.dem as recorded by
+	// tv_autorecord
+	compactDateRe = regexp.MustCompile(`(?:^|\D)(\d{4})(\d{2})(\d{2})[-_](\d{2})(\d{2})(\d{2})(?:\D|$)`)
+	// 2021-6-6_pug_de_overpass.dem, optionally followed by the time like
+	// 2021-06-06_18-30
+	dateRe = regexp.MustCompile(`(?:^|\D)(\d{4})-(\d{1,2})-(\d{1,2})(?:[_ T-](\d{1,2})[-:.h](\d{2}))?`)
+)
+
+// matchTimeFromPath returns the local time in the name of a demo file, if
+// it has one
+func matchTimeFromPath(path string) (time.Time, bool) {
+	name := filepath.Base(path)
+	m := compactDateRe.FindStringSubmatch(name)
+	if m == nil {
+		if m = dateRe.FindStringSubmatch(name); m == nil {
+			return time.Time{}, false
+		}
+	}
+
+	var v [5]int
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+	t := time.Date(v[0], time.Month(v[1]), v[2], v[3], v[4], 0, 0, time.Local)
+	if len(m) > 6 {
+		sec, _ := strconv.Atoi(m[6])
+		t = t.Add(time.Duration(sec) * time.Second)
+	}
+	// Reject dates like 2021-13-40 that time.Date normalizes
+	if int(t.Month()) != v[1] || t.Day() != v[2] || v[3] > 23 || v[4] > 59 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// matchTimeOfFile returns the time of the match in the demo file at path,
+// from the file name or else the modification time of the file
+func matchTimeOfFile(path string, info os.FileInfo) time.Time {
+	if t, ok := matchTimeFromPath(path); ok {
+		return t
+	}
+	return info.ModTime()
+}
+
+// MatchIDFromPath returns the match id of a demo file, the part of the file
+// name before the first underscore
+func MatchIDFromPath(path string) string {
+	return strings.Split(filepath.Base(path), "_")[0]
+}
+
+// playersBySteamID returns the demoinfocs player with the steam id, nil if
+// there is none
+func (p *DemoParser) playersBySteamID(steamID uint64) *common.Player {
+	for _, v := range p.parser.GameState().Participants().All() {
+		if playerID(v) == steamID {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *DemoParser) calculate() {
+	if p.Match.General.GameMode == "" {
+		p.setGameMode(0)
+	}
+	p.Match.General.MaxRounds, _ = p.roundLimits()
+	settings := p.Match.General.GameMode.settings()
+	if p.Match.General.GameMode != ModeCompetitive {
+		p.warn("rating of ", p.Match.General.GameMode, " matches uses the competitive baselines")
+	}
+	p.setPeriodScores()
+	p.setDurations()
+	teamAPlayers := make([]uint64, 4)
+	teamBPlayers := make([]uint64, 4)
+	for _, player := range p.Match.Players.Players {
+		if player.TeamChar == "A" {
+			teamAPlayers = append(teamAPlayers, player.Steamid64)
+		} else {
+			teamBPlayers = append(teamBPlayers, player.Steamid64)
+		}
+	}
+	for k, player := range p.Match.Players.Players {
+		// Per-round averages are taken over the rounds the player was on a
+		// team for, substitutes and players that left only count their own
+		roundTotal := player.RoundsPlayed
+		// Set Steam Id
+		authserver := (player.Steamid64 - 76561197960265728) & 1
+		authid := (player.Steamid64 - 76561197960265728 - authserver) / 2
+		steamid := ""
+		steamid = fmt.Sprintf("STEAM_1:%d:%d", authserver, authid)
+		if player.IsBot {
+			steamid = "BOT"
+		}
+		p.Match.Players.Players[k].SteamId = steamid
+
+		// Set Kills, Deaths, Assists, MVPs
+		if pl := p.playersBySteamID(player.Steamid64); pl != nil {
+			sp := &p.Match.Players.Players[k]
+			score := p.score(pl)
+			sp.Kills, sp.Deaths, sp.Assists, sp.MVPs = p.state.KnifeScores[player.Steamid64].subtract(
+				score.Kills, score.Deaths, score.Assists, score.MVPs)
+		} else {
+			p.fail("no scoreboard found for player ", player.Steamid64)
+		}
+
+		var playerAdr = 0
+		for k, v := range p.Match.Players.Players[k].PlayerDamages.Damages {
+			if player.TeamChar == "A" {
+				if itemExists(teamBPlayers, k) {
+					playerAdr += v
+				}
+			} else {
+				if itemExists(teamAPlayers, k) {
+					playerAdr += v
+				}
+			}
+		}
+		if roundTotal > 0 {
+			p.Match.Players.Players[k].Adr = float64(playerAdr) / float64(roundTotal)
+		}
+
+		// Calculate player's K/D
+		if p.Match.Players.Players[k].Deaths != 0 {
+			p.Match.Players.Players[k].Kd = float64(p.Match.Players.Players[k].Kills) / float64(p.Match.Players.Players[k].Deaths)
+		}
+
+		for _, round := range p.Match.Rounds {
+			if round.Knife || round.Incomplete {
+				continue
+			}
+
+			// Find player's kills and hs
+			roundKills := 0
+			for _, kill := range append(round.BKills, round.AKills...) {
+				if kill.Killer.Steamid64 == player.Steamid64 {
+					if kill.IsHeadshot {
+						p.Match.Players.Players[k].Headshots++
+					}
+					roundKills++
+					// p.Match.Players.Players[k].WeaponStats.AddKill(kill.KillerWeapon, kill)
+					// p.Match.Players.Players[k].WeaponStats.Kills[kill.KillerWeapon]++
+				}
+			}
+
+			// Calculate player's he percentage
+			if p.Match.Players.Players[k].Kills != 0 {
+				p.Match.Players.Players[k].Hsprecent = float64(p.Match.Players.Players[k].Headshots) / float64(p.Match.Players.Players[k].Kills) * 100
+			}
+
+			// Set player's 3k, 4k, 5k rounds. More kills than players on a
+			// team, e.g. of team mates, count as the most there can be
+			if roundKills > settings.TeamSize {
+				roundKills = settings.TeamSize
+			}
+			if roundKills >= 5 {
+				p.Match.Players.Players[k].Rounds5K++
+			}
+			if roundKills == 4 {
+				p.Match.Players.Players[k].Rounds4K++
+			}
+			if roundKills == 3 {
+				p.Match.Players.Players[k].Rounds3K++
+			}
+			if roundKills == 2 {
+				p.Match.Players.Players[k].Rounds2K++
+			}
+			if roundKills == 1 {
+				p.Match.Players.Players[k].Rounds1K++
+			}
+		}
+
+		// Per-round averages need at least one completed round
+		if roundTotal < 1 {
+			continue
+		}
+
+		var killRating float64
+		var survivalRating float64
+		var multiRating float64
+
+		// Calculate HLTV rating against the competitive averages
+
+		// Kills/Rounds/AverageKPR
+		kpr := float64(p.Match.Players.Players[k].Kills) / float64(roundTotal)
+		killRating = kpr / averageKpr
+		// (Rounds-Deaths)/Rounds/AverageSPR
+		survivalRating = float64(roundTotal-p.Match.Players.Players[k].Deaths) / (float64(roundTotal)) / averageSpr
+		// (1K + 4*2K + 9*3K + 16*4K + 25*5K)/Rounds/AverageRMK
+		multiRating = float64(p.Match.Players.Players[k].Rounds1K+
+			4*p.Match.Players.Players[k].Rounds2K+
+			9*p.Match.Players.Players[k].Rounds3K+
+			16*p.Match.Players.Players[k].Rounds4K+
+			25*p.Match.Players.Players[k].Rounds5K) / float64(roundTotal) / averageRmk
+		var rating = (killRating + 0.7*survivalRating + multiRating) / 2.7
+
+		p.Match.Players.Players[k].Rating = rating
+		p.Match.Players.Players[k].Kpr = kpr
+		p.Match.Players.Players[k].Rws /= float64(roundTotal)
+		p.Match.Players.Players[k].Efpr = float64(p.Match.Players.Players[k].EffFlashes) / float64(roundTotal)
+	}
+}
+
+// csgoDemoProtocol is the demo protocol version of CSGO demos
+const csgoDemoProtocol = 4
+
+func (p *DemoParser) setGeneral() error {
+
+	var header common.DemoHeader
+	var err error
+
+	if header, err = p.parser.ParseHeader(); err != nil {
+		// CS2 demos start with a different filestamp
+		if strings.HasPrefix(header.Filestamp, "PBDEMS") {
+			return ErrUnsupportedDemoVersion
+		}
+		return demoError(err)
+	}
+
+	if header.Protocol != csgoDemoProtocol {
+		return ErrUnsupportedDemoVersion
+	}
+
+	p.Match.General.MapName = header.MapName
+	p.Match.General.MapIconURL = header.MapName
+	p.Match.General.MatchTime = p.MatchTime
+	if p.MatchTime.IsZero() {
+		p.Match.General.MatchTime = time.Now()
+	}
+	p.Match.General.ServerName = header.ServerName
+	p.Match.General.ClientName = header.ClientName
+	p.Match.General.DemoType = demoType(header)
+	p.Match.General.PlaybackTicks = header.PlaybackTicks
+	p.Match.General.PlaybackFrames = header.PlaybackFrames
+	p.Match.General.DemoDuration = header.PlaybackTime
+	if header.PlaybackTime > 0 {
+		// The playback time is stored as a float32, round off its error
+		tickRate := float64(header.PlaybackTicks) / header.PlaybackTime.Seconds()
+		p.Match.General.TickRate = math.Round(tickRate*100) / 100
+	}
+	//p.Match.General.DemoLinkURL = "https:TODO/"
+	p.Match.General.ScoreA = 0
+	p.Match.General.ScoreB = 0
+
+	return nil
+}
+
+// demoType returns whether the demo was recorded by GOTV or by a player. The
+// client of GOTV demos is named "GOTV Demo", of POV demos after the player
+func demoType(header common.DemoHeader) DemoType {
+	if header.ClientName == "GOTV Demo" {
+		return DemoTypeGOTV
+	}
+	return DemoTypePOV
+}
+
+// setDurations sets the tickrate the server ran on and the durations of the
+// demo and of the match. Demos without playback time in the header, like
+// broadcasts and truncated demos, last as long as was parsed
+func (p *DemoParser) setDurations() {
+	if tickRate := p.parser.TickRate(); tickRate > 0 {
+		p.Match.General.TickRate = tickRate
+	}
+	if p.Match.General.DemoDuration == 0 {
+		p.Match.General.DemoDuration = p.parser.CurrentTime()
+	}
+	if p.state.LastRoundEnd > p.state.MatchStartTime {
+		p.Match.General.MatchDuration = p.state.LastRoundEnd - p.state.MatchStartTime
+	}
+}
+
+// NewScoreBoardPlayer constructor for a ScoreboardPlayer. Initializes some
+// values with defaults
+func (p *DemoParser) NewScoreBoardPlayer(player *common.Player) ScoreboardPlayer {
+
+	name := player.Name
+	if player.IsBot {
+		name = botName(player)
+	}
+	// Before the match started the team playing CT is team A
+	teamA := p.state.TeamA
+	if teamA == common.TeamUnassigned {
+		teamA = common.TeamCounterTerrorists
+	}
+	team := "A"
+	if player.Team != teamA {
+		team = "B"
+	}
+
+	return ScoreboardPlayer{
+		IsBot:            player.IsBot,
+		IsAMember:        player.Team == teamA,
+		TeamChar:         team,
+		Name:             name,
+		Rank:             0,
+		Atag:             player.ClanTag(),
+		Steamid64:        playerID(player),
+		Kills:            0,
+		Deaths:           0,
+		Assists:          0,
+		Kd:               0,
+		Adr:              0,
+		Rws:              0,
+		Rating:           0,
+		Hsprecent:        0,
+		Firstkills:       0,
+		Firstdeaths:      0,
+		Tradekills:       0,
+		Tradedeaths:      0,
+		Tradefirstkills:  0,
+		Tradefirstdeaths: 0,
+		Roundswonv5:      0,
+		Roundswonv4:      0,
+		Roundswonv3:      0,
+		Roundswonv2:      0,
+		Rounds5K:         0,
+		Rounds4K:         0,
+		Rounds3K:         0,
+		Rounds2K:         0,
+		Rounds1K:         0,
+		WeaponStats:      NewWeaponstats(),
+		PlayerDamages:    NewPlayerDamages(),
+	}
+}
+
+func (p *DemoParser) handlerWeaponFire(e events.WeaponFire) {
+
+	if p.parser.GameState().IsWarmupPeriod() {
+		return
+	}
+
+	if e.Shooter == nil {
+		return
+	}
+
+	shooterPl, _ := p.controller(e.Shooter)
+	p.playerByID(shooterPl)
+	shooter, err := p.Match.Players.PlayerNumByID(playerID(shooterPl))
+
+	if err != nil {
+		p.fail("skipped shot: ", err)
+		return
+	}
+	p.Match.Players.Players[shooter].WeaponStats.addShot(e)
+	if e.Weapon != nil && e.Weapon.Type != common.EqKnife {
+		p.state.RoundGunFired = true
+	}
+
+}
+
+func (p *DemoParser) handlerKill(e events.Kill) {
+
+	if e.Killer == nil || e.Victim == nil {
+		return
+	}
+
+	// Skip all calculations for kills during warmup
+	if p.parser.GameState().IsWarmupPeriod() {
+		p.state.WarmupKills = append(p.state.WarmupKills, e)
+		return
+	}
+
+	if !p.roundStarted() {
+		p.warn("skipped kill before the first round")
+		return
+	}
+
+	// Kills and deaths of a bot taken over by a player are credited to the
+	// player
+	killerPl, botControlled := p.controller(e.Killer)
+	victimPl, _ := p.controller(e.Victim)
+
+	// Find killer
+	killer := p.playerByID(killerPl)
+	killerNum, err := p.Match.Players.PlayerNumByID(playerID(killerPl))
+	if err != nil {
+		p.fail("skipped kill: ", err)
+		return
+	}
+
+	// Find victim
+	victim := p.playerByID(victimPl)
+	victimNum, err := p.Match.Players.PlayerNumByID(playerID(victimPl))
+	if err != nil {
+		p.fail("skipped kill: ", err)
+		return
+	}
+
+	if e.Weapon == nil {
+		p.warn("kill of ", victim.Name, " without a weapon, counted as unknown")
+	}
+	p.Match.Players.Players[killerNum].WeaponStats.addKill(e)
+
+	if e.IsHeadshot {
+		p.Match.Players.Players[killerNum].WeaponStats.addHeadshot(e)
+	}
+
+	kill := RoundKill{
+		Time:          p.parser.CurrentTime(),
+		IsHeadshot:    e.IsHeadshot,
+		KillerWeapon:  equipmentType(e.Weapon),
+		Killer:        killer,
+		Victim:        victim,
+		BotControlled: botControlled,
+	}
+
+	if e.Assister != nil {
+		assisterPl, _ := p.controller(e.Assister)
+		assister := p.playerByID(assisterPl)
+		p.Match.Players.addAssist(playerID(assisterPl))
+		kill.Assister = assister
+	}
+
+	// Find fistkills and firstdeaths
+	if killerPl.Team == p.state.TeamA {
+
+		// Check if it's the first kill of the round
+		if len(p.Match.Rounds[p.state.Round-1].AKills) == 0 {
+			p.Match.Players.Players[killerNum].Firstkills++
+			p.Match.Players.Players[victimNum].Firstdeaths++
+		}
+
+		for _, v := range p.Match.Rounds[p.state.Round-1].AKills {
+			if v.Killer.Steamid64 == playerID(victimPl) && ((p.parser.CurrentTime() - v.Time) < (5 * time.Second)) {
+				p.Match.Players.Players[killerNum].Tradekills++
+				p.Match.Players.Players[victimNum].Tradedeaths++
+
+				if len(p.Match.Rounds[p.state.Round-1].AKills) == 0 {
+					p.Match.Players.Players[killerNum].Tradefirstkills++
+					p.Match.Players.Players[victimNum].Tradefirstdeaths++
+				}
+			}
+		}
+
+		// Append to akills
+		p.Match.Rounds[p.state.Round-1].AKills = append(p.Match.Rounds[p.state.Round-1].AKills, kill)
+	} else {
+
+		// Check if it's the first kill of the round
+		if len(p.Match.Rounds[p.state.Round-1].BKills) == 0 {
+			p.Match.Players.Players[killerNum].Firstkills++
+			p.Match.Players.Players[victimNum].Firstdeaths++
+		}
+
+		for _, v := range p.Match.Rounds[p.state.Round-1].BKills {
+			if v.Killer.Steamid64 == playerID(victimPl) && ((p.parser.CurrentTime() - v.Time) < (5 * time.Second)) {
+				p.Match.Players.Players[killerNum].Tradekills++
+				p.Match.Players.Players[victimNum].Tradedeaths++
+			}
+
+			if len(p.Match.Rounds[p.state.Round-1].BKills) == 0 {
+				p.Match.Players.Players[killerNum].Tradefirstkills++
+				p.Match.Players.Players[victimNum].Tradefirstdeaths++
+			}
+		}
+
+		// Append to bkills
+		p.Match.Rounds[p.state.Round-1].BKills = append(p.Match.Rounds[p.state.Round-1].BKills, kill)
+	}
+
+	// Find 1v5 down to the smallest clutch of the game mode
+	if p.matesAlive(killerPl) == 1 {
+		opponents := p.matesAlive(victimPl)
+		if opponents >= p.Match.General.GameMode.settings().MinClutch {
+			p.Match.Players.Players[killerNum].addClutch(opponents)
+		}
+	}
+}
+
+func (p DemoParser) matesAlive(player *common.Player) int {
+	alive := 0
+	for _, v := range p.parser.GameState().Participants().Playing() {
+		if v.IsAlive() && v.Team == player.Team {
+			alive++
+		}
+	}
+	return alive
+}
+
+func (p *DemoParser) handlerPlayerHurt(e events.PlayerHurt) {
+
+	if e.Attacker == nil || e.Player == nil {
+		return
+	}
+	attacker, _ := p.controller(e.Attacker)
+	victimPl, _ := p.controller(e.Player)
+
+	for k, v := range p.Match.Players.Players {
+		if v.Steamid64 == playerID(attacker) {
+
+			// Add damage stats for weapon
+			p.Match.Players.Players[k].WeaponStats.addDamage(e)
+
+			// Add hit stats for weapon
+			p.Match.Players.Players[k].WeaponStats.addHit(e)
+
+			// Add damage stats for PvP
+			_ = p.playerByID(victimPl)
+			victimNum, err := p.Match.Players.PlayerNumByID(playerID(victimPl))
+
+			if err != nil {
+				p.fail("skipped damage: ", err)
+				return
+			}
+
+			var damage = e.HealthDamage
+			if damage > e.Player.Health() {
+				damage = e.Player.Health()
+			}
+			p.Match.Players.Players[k].addDamage(damage, &p.Match.Players.Players[victimNum])
+
+			// Add damage to attackers round share for RWS.
+			// Only count attacks on the opposing team.
+			if attacker.Team != victimPl.Team {
+				var dmg = e.HealthDamage
+				// Cap damage at player health for a max total damage of 500
+				if dmg > e.Player.Health() {
+					dmg = e.Player.Health()
+				}
+				p.Match.RdDamages.addDamage(dmg, playerID(attacker))
+			}
+
+			return
+		}
+	}
+}
+
+func (p *DemoParser) handlerChatMessage(e events.ChatMessage) {
+	msg := ChatMessage{
+		Round:   p.state.Round,
+		Time:    p.parser.CurrentTime(),
+		AllChat: e.IsChatAll,
+		Text:    e.Text,
+	}
+	if len(p.ChatWords) > 0 {
+		msg.Matches = matchWords(e.Text, p.ChatWords)
+		if len(msg.Matches) == 0 {
+			return
+		}
+	}
+
+	// Messages of the server have no sender
+	if e.Sender != nil {
+		msg.Steamid64 = playerID(e.Sender)
+		msg.Name = e.Sender.Name
+		// Spectators and messages before the teams are known have no team
+		playing := e.Sender.Team == common.TeamCounterTerrorists || e.Sender.Team == common.TeamTerrorists
+		switch {
+		case !playing || p.state.TeamA == common.TeamUnassigned:
+		case e.Sender.Team == p.state.TeamA:
+			msg.TeamChar = "A"
+		default:
+			msg.TeamChar = "B"
+		}
+	}
+	p.Match.Chat = append(p.Match.Chat, msg)
+}
+
+// matchWords returns the words and phrases of the list found in the text as
+// whole words, ignoring case and punctuation. "gg wp" matches "GG, wp!"
+func matchWords(text string, words []string) []string {
+	normalized := " " + normalizeWords(text) + " "
+
+	var matches []string
+	for _, w := range words {
+		phrase := normalizeWords(w)
+		if phrase != "" && strings.Contains(normalized, " "+phrase+" ") {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+// normalizeWords returns the lower case words of the text separated by
+// single spaces
+func normalizeWords(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Handlers
+func (p *DemoParser) handlerRankUpdate(e events.RankUpdate) {
+
+	for k, v := range p.Match.Players.Players {
+		if v.Steamid64 == e.SteamID64() {
+			p.Match.Players.Players[k].Rank = e.RankNew
+			return
+		}
+	}
+	p.warn("player not found setting rank")
+}
+
+func (p *DemoParser) handlerMatchStart(e events.MatchStart) {
+	// A restart resets the in-game scoreboard
+	p.state.KnifeScores = nil
+	// We will treat the team playing CT first as "TeamChar A"
+	p.state.TeamA = common.TeamCounterTerrorists
+	p.Match.MatchValid = true
+	p.state.MatchStarted = true
+	p.state.MatchStartRound = p.state.Round
+	p.state.MatchStartTime = p.parser.CurrentTime()
+
+	playing := p.parser.GameState().Participants().Playing()
+	p.setGameMode(len(playing))
+
+	// Add all players to the match, a restart keeps the players added
+	// before
+	for _, ct := range playing {
+		if _, err := p.Match.Players.PlayerNumByID(playerID(ct)); err == nil {
+			continue
+		}
+		player := p.NewScoreBoardPlayer(ct)
+
+		p.Match.Players.Players = append(p.Match.Players.Players, player)
+	}
+	p.Match.RdDamages.RdDamages = NewRdDamages()
+}
+
+// roundLimits returns the maximum number of rounds of the match and of each
+// overtime from the convars of the server, the defaults of the game mode if
+// they aren't set
+func (p *DemoParser) roundLimits() (maxRounds, overtimeMaxRounds int) {
+	conVars := p.parser.GameState().Rules().ConVars()
+	settings := p.Match.General.GameMode.settings()
+	return conVarInt(conVars, "mp_maxrounds", settings.MaxRounds),
+		conVarInt(conVars, "mp_overtime_maxrounds", settings.OvertimeMaxRounds)
+}
+
+// setGameMode sets the game mode of the match from the convars. Demos
+// without them are wingman if there are no more than 4 players at the
+// start of the match and competitive otherwise
+func (p *DemoParser) setGameMode(players int) {
+	mode, ok := gameModeFromConVars(p.parser.GameState().Rules().ConVars())
+	switch {
+	case ok:
+	case players > 0 && players <= 4:
+		mode = ModeWingman
+	default:
+		mode = ModeCompetitive
+	}
+	p.Match.General.GameMode = mode
+	p.log().Debug("game mode ", mode)
+}
+
+// conVarInt returns the positive integer value of the convar, def if it is
+// not set
+func conVarInt(conVars map[string]string, name string, def int) int {
+	v, err := strconv.Atoi(conVars[name])
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// roundPeriod returns the half and the overtime of the nth round of the
+// match. Rounds of regulation time have overtime 0, every overtime has two
+// halves as well
+func (p *DemoParser) roundPeriod(n int) (half, overtime int) {
+	if n < 1 {
+		return 0, 0
+	}
+	maxRounds, overtimeMaxRounds := p.roundLimits()
+	if n <= maxRounds {
+		if n <= (maxRounds+1)/2 {
+			return 1, 0
+		}
+		return 2, 0
+	}
+	n -= maxRounds + 1
+	overtime = n/overtimeMaxRounds + 1
+	if n%overtimeMaxRounds < (overtimeMaxRounds+1)/2 {
+		return 1, overtime
+	}
+	return 2, overtime
+}
+
+// isKnifeRound reports whether the ended round is a knife round. Only the
+// first round of the match is checked, it is a knife round if there were
+// kills but no weapon other than the knife was fired
+func (p *DemoParser) isKnifeRound() bool {
+	if !p.matchStarted() || p.state.Round != p.state.MatchStartRound+1 || p.state.RoundGunFired {
+		return false
+	}
+	r := p.Match.Rounds[p.state.Round-1]
+	return len(r.AKills)+len(r.BKills) > 0
+}
+
+// discardKnifeRound marks the ended round as a knife round and resets the
+// stats gathered so far, the match starts with the next round
+func (p *DemoParser) discardKnifeRound(winner common.Team) {
+	p.log().Info("knife round left out of the stats")
+	r := &p.Match.Rounds[p.state.Round-1]
+	r.Knife = true
+	r.Half = 0
+	p.state.MatchStartRound = p.state.Round
+	p.state.MatchStartTime = p.parser.CurrentTime()
+
+	p.state.KnifeScores = map[uint64]playerScore{}
+	p.state.KnifeWonByA = winner == p.state.TeamA
+	for k, pl := range p.Match.Players.Players {
+		if gp := p.playersBySteamID(pl.Steamid64); gp != nil {
+			p.state.KnifeScores[pl.Steamid64] = scoreOf(gp)
+		}
+		p.Match.Players.Players[k] = ScoreboardPlayer{
+			IsBot:         pl.IsBot,
+			IsAMember:     pl.IsAMember,
+			TeamChar:      pl.TeamChar,
+			Steamid64:     pl.Steamid64,
+			Name:          pl.Name,
+			Atag:          pl.Atag,
+			Rank:          pl.Rank,
+			Substitute:    pl.Substitute,
+			WeaponStats:   NewWeaponstats(),
+			PlayerDamages: NewPlayerDamages(),
+		}
+		p.Match.RdDamages.resetDamage(pl.Steamid64)
+	}
+}
+
+// setPeriodScores sets the score of each half of regulation time and of each
+// overtime from the ended rounds
+func (p *DemoParser) setPeriodScores() {
+	p.Match.General.Halves = nil
+	p.Match.General.Overtimes = nil
+	for _, r := range p.Match.Rounds {
+		if r.Half == 0 || r.Incomplete || r.TeamWon == common.TeamUnassigned {
+			continue
+		}
+		periods := &p.Match.General.Halves
+		idx := r.Half - 1
+		if r.Overtime > 0 {
+			periods = &p.Match.General.Overtimes
+			idx = r.Overtime - 1
+		}
+		for len(*periods) <= idx {
+			*periods = append(*periods, PeriodScore{})
+		}
+		if r.AWonRound {
+			(*periods)[idx].ScoreA++
+		} else {
+			(*periods)[idx].ScoreB++
+		}
+	}
+}
+
+func (p *DemoParser) handlerMatchStartedChanged(e events.MatchStartedChanged) {
+	// The end of the warmup resets the in-game scoreboard
+	if e.NewIsStarted {
+		p.state.KnifeScores = nil
+	}
+}
+
+// checkScoreboardReset forgets the scoreboard of the knife round once the
+// in-game scoreboard was reset, e.g. by mp_restartgame. The scoreboard only
+// grows otherwise, so fewer kills and deaths than after the knife round
+// mean it was reset
+func (p *DemoParser) checkScoreboardReset() {
+	if p.state.KnifeScores == nil {
+		return
+	}
+	var knife, live int
+	for id, ks := range p.state.KnifeScores {
+		if gp := p.playersBySteamID(id); gp != nil {
+			knife += ks.Kills + ks.Deaths
+			live += gp.Kills() + gp.Deaths()
+		}
+	}
+	if live < knife {
+		p.log().Debug("scoreboard reset after the knife round")
+		p.state.KnifeScores = nil
+	}
+}
+
+// matchStarted reports whether the match has started, players joining after
+// that are substitutes
+func (p *DemoParser) matchStarted() bool {
+	return p.state.MatchStarted
+}
+
+// countRoundPlayed credits the ended round to every player on a team. The
+// round started before the match begins is left out, just like rounds cut
+// off by the end of a truncated demo which never end
+func (p *DemoParser) countRoundPlayed() {
+	if !p.matchStarted() || p.state.Round <= p.state.MatchStartRound {
+		return
+	}
+	counted := map[uint64]bool{}
+	for _, pl := range p.parser.GameState().Participants().Playing() {
+		if pl.Team != common.TeamCounterTerrorists && pl.Team != common.TeamTerrorists {
+			continue
+		}
+		if counted[playerID(pl)] {
+			continue
+		}
+		counted[playerID(pl)] = true
+
+		// Creates players that joined during the match
+		p.playerByID(pl)
+		playerNum, err := p.Match.Players.PlayerNumByID(playerID(pl))
+		if err != nil {
+			p.fail("skipped rounds played: ", err)
+			continue
+		}
+		p.Match.Players.Players[playerNum].RoundsPlayed++
+	}
+}
+
+func (p *DemoParser) handlerBotTakenOver(e events.BotTakenOver) {
+	bot := e.Taker.ControlledBot()
+	if bot == nil {
+		p.warn("bot taken over by ", e.Taker.Name, " not found")
+		return
+	}
+	if p.state.BotControllers == nil {
+		p.state.BotControllers = map[uint64]*common.Player{}
+	}
+	p.state.BotControllers[playerID(bot)] = e.Taker
+}
+
+// controller returns the player in control of pl and whether that is a
+// player that took over a bot
+func (p *DemoParser) controller(pl *common.Player) (*common.Player, bool) {
+	if !pl.IsBot {
+		return pl, pl.IsControllingBot()
+	}
+	if taker, ok := p.state.BotControllers[playerID(pl)]; ok {
+		return taker, true
+	}
+	return pl, false
+}
+
+func (p *DemoParser) handlerPlayerConnect(e events.PlayerConnect) {
+	p.addConnection(e.Player, true)
+}
+
+func (p *DemoParser) handlerPlayerDisconnected(e events.PlayerDisconnected) {
+	p.addConnection(e.Player, false)
+}
+
+// addConnection records a player connecting or disconnecting after the
+// match started. Bots are added and kicked as players come and go and are
+// left out
+func (p *DemoParser) addConnection(player *common.Player, connected bool) {
+	if player == nil || player.IsBot || !p.matchStarted() {
+		return
+	}
+	p.Match.Connections = append(p.Match.Connections, Connection{
+		Round:     p.state.Round,
+		Time:      p.parser.CurrentTime(),
+		Steamid64: playerID(player),
+		Name:      player.Name,
+		Connected: connected,
+	})
+}
+
+func (p *DemoParser) handlerRoundStart(e events.RoundStart) {
+
+	p.state.RoundOngoing = true
+	// Bots are only taken over until the end of the round
+	p.state.BotControllers = nil
+	p.state.RoundGunFired = false
+	p.checkScoreboardReset()
+
+	// An new round has started, increase counter and add it to slice of the
+	// output. The counter should be increased here and *not* in the RoundEnd
+	// handler, sice there might happen things "between" the rounds, i.e in the
+	// time when a round has ended but the new one has not yet started
+	p.state.Round++
+	p.saveRoundStart()
+
+	var a_member_steamID uint64
+	/*
+	 * Each round, find the first player on team A and use them to determine
+	 * whether team A is CT or T.
+	 */
+	for _, pl := range p.Match.Players.Players {
+		if pl.IsAMember {
+			a_member_steamID = pl.Steamid64
+			break
+		}
+	}
+
+	for _, ct := range p.parser.GameState().TeamCounterTerrorists().Members() {
+		if playerID(ct) == a_member_steamID {
+			p.state.TeamA = common.TeamCounterTerrorists
+			break
+		}
+	}
+
+	for _, t := range p.parser.GameState().TeamTerrorists().Members() {
+		if playerID(t) == a_member_steamID {
+			p.state.TeamA = common.TeamTerrorists
+			break
+		}
+	}
+
+	round := ScoreboardRound{}
+	if p.matchStarted() {
+		round.Half, round.Overtime = p.roundPeriod(p.state.Round - p.state.MatchStartRound)
+	}
+	p.Match.Rounds = append(p.Match.Rounds, round)
+
+}
+
+func (p *DemoParser) handlerBombPlanted(e events.BombPlanted) {
+	if e.Player == nil || !p.roundStarted() {
+		return
+	}
+	p.Match.Rounds[p.state.Round-1].BombPlanter = playerID(e.Player)
+}
+
+func (p *DemoParser) handlerBombDefused(e events.BombDefused) {
+	if e.Player == nil || !p.roundStarted() {
+		return
+	}
+	p.Match.Rounds[p.state.Round-1].BombDefuser = playerID(e.Player)
+}
+
+func (p *DemoParser) handlerBombExplode(e events.BombExplode) {
+}
+
+func (p *DemoParser) handlerScoreUpdated(e events.ScoreUpdated) {
+
+	if !p.roundStarted() {
+		return
+	}
+
+	scoreA, scoreB, ok := p.teamScores(p.parser.GameState().TeamCounterTerrorists().Score(),
+		p.parser.GameState().TeamTerrorists().Score())
+	if !ok {
+		return
+	}
+
+	p.Match.Rounds[p.state.Round-1].ScoreA = scoreA
+	p.Match.Rounds[p.state.Round-1].ScoreB = scoreB
+	p.Match.General.ScoreA = scoreA
+	p.Match.General.ScoreB = scoreB
+}
+
+// teamScores returns the scores of team A and B from the game score of the
+// sides, false as long as the sides of the teams aren't known. The game
+// score counts the knife round until it is reset
+func (p *DemoParser) teamScores(scoreCT, scoreT int) (scoreA, scoreB int, ok bool) {
+	switch p.state.TeamA {
+	case common.TeamCounterTerrorists:
+		scoreA, scoreB = scoreCT, scoreT
+	case common.TeamTerrorists:
+		scoreA, scoreB = scoreT, scoreCT
+	default:
+		return 0, 0, false
+	}
+
+	if p.state.KnifeScores != nil {
+		if p.state.KnifeWonByA && scoreA > 0 {
+			scoreA--
+		} else if !p.state.KnifeWonByA && scoreB > 0 {
+			scoreB--
+		}
+	}
+	return scoreA, scoreB, true
+}
+
+func (p *DemoParser) handlerFrameDone(e events.FrameDone) {
+	p.reportProgress()
+}
+
+func (p *DemoParser) handlerRoundEndSnapshot(e events.RoundEnd) {
+	if !p.roundStarted() {
+		return
+	}
+	m, err := p.snapshot()
+	if err != nil {
+		p.log().Warning("snapshot of the match failed: ", err)
+		return
+	}
+	p.OnRoundEnd(m)
+}
+
+// snapshot returns a copy of the match parsed so far with the stats
+// calculated over the rounds up to now. The match itself is left as is, the
+// stats are only calculated once at the end of the demo
+func (p *DemoParser) snapshot() (*InfoStruct, error) {
+	b, err := json.Marshal(p.Match)
+	if err != nil {
+		return nil, err
+	}
+	m := &InfoStruct{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+
+	live := *p
+	live.Match = m
+	live.calculate()
+	return m, nil
+}
+
+// reportProgress calls OnProgress if the progress changed since it was last
+// reported
+func (p *DemoParser) reportProgress() {
+	if p.OnProgress == nil {
+		return
+	}
+	progress := Progress{
+		Percent: int(p.parser.Progress() * 100),
+		Round:   p.state.Round,
+		ScoreA:  p.Match.General.ScoreA,
+		ScoreB:  p.Match.General.ScoreB,
+	}
+	if progress.Percent > 100 {
+		progress.Percent = 100
+	}
+	if progress != p.state.Progress {
+		p.state.Progress = progress
+		p.OnProgress(progress)
+	}
+}
+
+func (p *DemoParser) handlerRoundEnd(e events.RoundEnd) {
+	if !p.state.RoundOngoing {
+		return
+	}
+	p.state.RoundOngoing = false
+	p.state.RoundsEnded++
+	p.state.LastRoundEnd = p.parser.CurrentTime()
+	var rdIdx = p.state.Round - 1
+	// Set the winning team
+	p.Match.Rounds[rdIdx].TeamWon = e.Winner
+
+	// The winner of the knife round only picks the side
+	if p.isKnifeRound() {
+		p.discardKnifeRound(e.Winner)
+		return
+	}
+	p.countRoundPlayed()
+
+	if e.Winner == p.state.TeamA {
+		p.Match.Rounds[rdIdx].AWonRound = true
+		p.Match.General.ScoreA++
+	} else {
+		p.Match.General.ScoreB++
+	}
+
+	// Calculate RWS for the winning team
+	var winningTeamDamage int
+	sharesLeft, bombShares := p.Match.General.GameMode.settings().rwsShares()
+	var winners []*common.Player
+
+	if e.Winner == common.TeamCounterTerrorists {
+		p.log().Debug("CounterTerrorists win")
+		// If the CTs won due to defuse, give defuser the bomb shares.
+		if e.Reason == events.RoundEndReasonBombDefused {
+			p.log().Debug("Bomb defusal")
+			var defuser = p.Match.Rounds[rdIdx].BombDefuser
+			playerNum, err := p.Match.Players.PlayerNumByID(defuser)
+			if err != nil {
+				p.fail("skipped RWS for defuser: ", err)
+			} else {
+				p.Match.Players.Players[playerNum].Rws += bombShares
+				sharesLeft -= bombShares
+			}
+		}
+
+		winners = p.parser.GameState().TeamCounterTerrorists().Members()
+
+	} else {
+		p.log().Debug("Terrorists win")
+		// If the Ts won due to bomb, give planter the bomb shares.
+		if e.Reason == events.RoundEndReasonTargetBombed {
+			p.log().Debug("Bomb explosion")
+			var planter = p.Match.Rounds[rdIdx].BombPlanter
+			playerNum, err := p.Match.Players.PlayerNumByID(planter)
+			if err != nil {
+				p.fail("skipped RWS for planter: ", err)
+			} else {
+				p.Match.Players.Players[playerNum].Rws += bombShares
+				sharesLeft -= bombShares
+			}
+		}
+		winners = p.parser.GameState().TeamTerrorists().Members()
+	}
+
+	// Find total damage for winning team to evenly split 100 RWS
+	for _, pl := range winners {
+		winningTeamDamage += p.Match.RdDamages.RdDamages.Damages[playerID(pl)]
+		//log.Info("Steamid ", pl.SteamID64)
+	}
+
+	p.log().Debug("Win reason: ", e.Reason, " total damage: ", winningTeamDamage)
+	p.Match.Rounds[rdIdx].WinReason = e.Reason
+	// Split the rest of the shares by damage.
+	for _, pl := range winners {
+		var d = p.Match.RdDamages.RdDamages.Damages[playerID(pl)]
+
+		playerNum, err := p.Match.Players.PlayerNumByID(playerID(pl))
+		if err != nil {
+			p.fail("skipped RWS: ", err)
+			continue
+		}
+
+		var rws = sharesLeft * float64(d) / float64(winningTeamDamage)
+		sharesLeft -= rws
+		p.Match.Players.Players[playerNum].Rws += rws
+
+		p.log().Debugln(p.Match.Players.Players[playerNum].Name, " has ", rws, " this round and ", p.Match.Players.Players[playerNum].Rws, " RWS this game.")
+	}
+
+	//ct := p.parser.GameState().TeamCounterTerrorists().Members()
+	//t := p.parser.GameState().TeamTerrorists().Members()
+	//for _, pl := range ct {
+	//	var d = p.Match.RdDamages.RdDamages.Damages[pl.SteamID64]
+	//	playerNum, _ := p.Match.Players.PlayerNumByID(pl.SteamID64)
+	//	me, _ := p.Match.Players.PlayerNumByID(76561197990376443)
+	//	if me == playerNum {
+	//		log.Println(rdIdx, " - ", p.Match.Players.Players[playerNum].Name, " has ", d, " TDH this round")
+	//	}
+	//}
+	//for _, pl := range t {
+	//	var d = p.Match.RdDamages.RdDamages.Damages[pl.SteamID64]
+	//	playerNum, _ := p.Match.Players.PlayerNumByID(pl.SteamID64)
+	//	me, _ := p.Match.Players.PlayerNumByID(76561197990376443)
+	//	if me == playerNum {
+	//		log.Println(rdIdx, " - ", p.Match.Players.Players[playerNum].Name, " has ", d, " TDH this round")
+	//	}
+	//}
+
+	// Reset all round damage
+	for _, pl := range p.Match.Players.Players {
+		p.Match.RdDamages.resetDamage(pl.Steamid64)
+	}
+}
+
+func (p *DemoParser) handlerPlayerFlashed(e events.PlayerFlashed) {
+	if e.Attacker == nil {
+		return
+	}
+	attacker, _ := p.controller(e.Attacker)
+	id, err := p.Match.Players.PlayerNumByID(playerID(attacker))
+	if err != nil {
+		return
+	}
+	if e.FlashDuration().Milliseconds() >= 2000 {
+		p.Match.Players.Players[id].EffFlashes++
+	}
+	p.Match.Players.Players[id].FlashDuration += e.FlashDuration().Milliseconds()
+}
+func itemExists(arrayType interface{}, item interface{}) bool {
+	arr := reflect.ValueOf(arrayType)
+
+	for i := 0; i < arr.Len(); i++ {
+		if arr.Index(i).Interface() == item {
+			return true
+		}
+	}
+
+	return false
+}